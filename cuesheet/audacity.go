@@ -0,0 +1,92 @@
+package cuesheet
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WriteAudacityLabels writes the tracks as an Audacity label track, one
+// "start<TAB>end<TAB>label" line per track with times in seconds
+// Each label ends where the next track in the same file starts; the last
+// track of a file has no known end and is written as a point label
+// Tracks without a title are labeled "Track NN"
+func (c *Cuesheet) WriteAudacityLabels(w io.Writer) error {
+	ws := bufio.NewWriter(w)
+	for i := range c.File {
+		tracks := c.File[i].Tracks
+		for j := range tracks {
+			start, err := tracks[j].StartPosition()
+			if err != nil {
+				continue
+			}
+			end := start
+			if j+1 < len(tracks) {
+				if next, err := tracks[j+1].StartPosition(); err == nil && next > start {
+					end = next
+				}
+			}
+			label := tracks[j].Title
+			if label == "" {
+				label = "Track " + FormatTrackNumber(tracks[j].TrackNumber)
+			}
+			ws.WriteString(formatSeconds(start) + "\t" + formatSeconds(end) + "\t" + label + eol)
+		}
+	}
+	return ws.Flush()
+}
+
+// formatSeconds formats a frame position as seconds with six decimals, as Audacity does
+func formatSeconds(f Frame) string {
+	return strconv.FormatFloat(f.ToSeconds(), 'f', 6, 64)
+}
+
+// ParseAudacityLabels builds a single-file cuesheet from an Audacity label
+// track export. Each "start<TAB>end<TAB>label" line becomes a track with
+// INDEX 01 at start, rounded to the nearest frame, and the label as its TITLE
+// The end column is ignored since a CUE track ends where the next one starts
+// Frequency lines of spectral labels (starting with a backslash) are skipped
+func ParseAudacityLabels(r io.Reader, fileName, fileType string) (*Cuesheet, error) {
+	scanner := bufio.NewScanner(r)
+	tracks := []Track{}
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "\\") {
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 3)
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("line %d: invalid start time %q", lineNumber, fields[0])
+		}
+		start := Frame(math.Round(seconds * FramesPerSecond))
+		if len(tracks) > 0 && start < tracks[len(tracks)-1].Index[0].Frame {
+			return nil, fmt.Errorf("line %d: label starts before the previous label", lineNumber)
+		}
+
+		title := ""
+		if len(fields) == 3 {
+			title = fields[2]
+		}
+		tracks = append(tracks, Track{
+			TrackNumber:   uint(len(tracks) + 1),
+			TrackDataType: "AUDIO",
+			Title:         title,
+			Index:         []TrackIndex{{Number: 1, Frame: start}},
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Cuesheet{
+		File: []File{{FileName: fileName, FileType: fileType, Tracks: tracks}},
+	}, nil
+}
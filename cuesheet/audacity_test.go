@@ -0,0 +1,74 @@
+package cuesheet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteAudacityLabels(t *testing.T) {
+	input := "FILE \"image.wav\" WAVE\n" +
+		"  TRACK 01 AUDIO\n    TITLE \"Intro\"\n    INDEX 01 00:00:00\n" +
+		"  TRACK 02 AUDIO\n    TITLE \"Main Theme\"\n    INDEX 00 03:20:00\n    INDEX 01 03:22:37\n" +
+		"  TRACK 03 AUDIO\n    INDEX 01 07:05:15\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := cuesheet.WriteAudacityLabels(&sb); err != nil {
+		t.Fatal(err)
+	}
+	expected := "0.000000\t202.493333\tIntro\n" +
+		"202.493333\t425.200000\tMain Theme\n" +
+		"425.200000\t425.200000\tTrack 03\n"
+	if sb.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
+
+func TestParseAudacityLabels(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		labels := "0.000000\t202.493333\tIntro\n202.493333\t425.200000\tMain Theme\n425.200000\t425.200000\tTrack 03\n"
+		cuesheet, err := ParseAudacityLabels(strings.NewReader(labels), "image.wav", "WAVE")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sb strings.Builder
+		if err := cuesheet.WriteAudacityLabels(&sb); err != nil {
+			t.Fatal(err)
+		}
+		if sb.String() != labels {
+			t.Errorf("round-trip mismatch:\n%s", sb.String())
+		}
+		if track, _ := cuesheet.GetTrack(2); track.Title != "Main Theme" || FormatFrame(track.Index[0].Frame) != "03:22:37" {
+			t.Errorf("unexpected track 2: %+v", track)
+		}
+	})
+
+	t.Run("HandEdited", func(t *testing.T) {
+		labels := "0\t0\tFirst\r\n\\\t100.0\t2000.0\n61.5\t61.5\tSecond Song\n\n125.00667\t130\n"
+		cuesheet, err := ParseAudacityLabels(strings.NewReader(labels), "mix.flac", "WAVE")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cuesheet.File[0].FileName != "mix.flac" || cuesheet.TrackCount() != 3 {
+			t.Fatalf("unexpected cuesheet: %+v", cuesheet)
+		}
+		expected := []struct{ title, start string }{{"First", "00:00:00"}, {"Second Song", "01:01:38"}, {"", "02:05:01"}}
+		for i, e := range expected {
+			track := cuesheet.File[0].Tracks[i]
+			if track.Title != e.title || FormatFrame(track.Index[0].Frame) != e.start || track.TrackNumber != uint(i+1) {
+				t.Errorf("track %d: got (%q, %s), expected (%q, %s)", i+1, track.Title, FormatFrame(track.Index[0].Frame), e.title, e.start)
+			}
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, labels := range []string{"abc\t1\tBad\n", "10\t10\tA\n5\t5\tB\n"} {
+			if _, err := ParseAudacityLabels(strings.NewReader(labels), "a.wav", "WAVE"); err == nil {
+				t.Errorf("expected an error for %q", labels)
+			}
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package cuesheet
+
+import "testing"
+
+func TestCompareStrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		min, max float64
+	}{
+		{"Identical", "Право на любовь", "Право на любовь", 1, 1},
+		{"BothEmpty", "", "", 1, 1},
+		{"CaseAndSpace", " She's Got A Way", "she's got a way ", 1, 1},
+		{"Similar", "She's Got A Way", "Shes Got a Way", 0.9, 0.99},
+		{"Mojibake", "Право на любовь", "РџСЂР°РІРѕ РЅР° Р»СЋР±РѕРІСЊ", 0, 0.2},
+		{"Unrelated", "Turn Around", "Falling of the Rain", 0, 0.3},
+		{"OneEmpty", "Title", "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := CompareStrings(tt.a, tt.b)
+			if score < tt.min || score > tt.max {
+				t.Errorf("CompareStrings(%q, %q) = %.3f, expected in [%.2f, %.2f]", tt.a, tt.b, score, tt.min, tt.max)
+			}
+			if reverse := CompareStrings(tt.b, tt.a); reverse != score {
+				t.Errorf("expected symmetric score, got %.3f and %.3f", score, reverse)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package cuesheet
+
+// Player identifies a media player whose CUE parsing quirks CheckCompatibility knows
+type Player int
+
+const (
+	PlayerFoobar2000 Player = iota
+	PlayerVLC
+	PlayerCmus
+)
+
+func (p Player) String() string {
+	switch p {
+	case PlayerFoobar2000:
+		return "foobar2000"
+	case PlayerVLC:
+		return "VLC"
+	case PlayerCmus:
+		return "cmus"
+	default:
+		return "unknown"
+	}
+}
+
+// CompatIssue describes a construct a player is likely to mishandle
+// TrackNumber is zero for issues that concern the whole sheet
+type CompatIssue struct {
+	Player      Player
+	TrackNumber uint
+	Message     string
+}
+
+func (i CompatIssue) String() string {
+	if i.TrackNumber == 0 {
+		return i.Player.String() + ": " + i.Message
+	}
+	return i.Player.String() + ": track " + FormatTrackNumber(i.TrackNumber) + ": " + i.Message
+}
+
+// CheckCompatibility reports constructs the target player is known to handle
+// poorly, for authoring sheets meant for that player:
+//
+//	foobar2000  PREGAP and POSTGAP are not rendered as silence, and a hidden
+//	            track before INDEX 01 of the first track is skipped
+//	VLC         only the first FILE of a multi-file sheet plays reliably, and
+//	            INDEX 00 pregaps are played as part of the following track
+//	cmus        sheets with more than one FILE are not supported, and tracks
+//	            without INDEX 01 or with a data mode are dropped
+func (c *Cuesheet) CheckCompatibility(target Player) []CompatIssue {
+	var issues []CompatIssue
+	report := func(track uint, message string) {
+		issues = append(issues, CompatIssue{target, track, message})
+	}
+
+	switch target {
+	case PlayerFoobar2000:
+		if c.HasHTOA() {
+			report(0, "hidden track before INDEX 01 of the first track is not playable")
+		}
+		for _, t := range c.AllTracks() {
+			if t.Pregap > 0 {
+				report(t.TrackNumber, "PREGAP silence is ignored")
+			}
+			if t.Postgap > 0 {
+				report(t.TrackNumber, "POSTGAP silence is ignored")
+			}
+		}
+	case PlayerVLC:
+		if len(c.File) > 1 {
+			report(0, "multi-file sheets may only play the first FILE")
+		}
+		for _, t := range c.AllTracks() {
+			if _, ok := t.GetPregapIndex(); ok && t.TrackNumber != c.firstTrackNumber() {
+				report(t.TrackNumber, "INDEX 00 pregap is played at the start of this track")
+			}
+		}
+	case PlayerCmus:
+		if len(c.File) > 1 {
+			report(0, "multi-file sheets are not supported")
+		}
+		for _, t := range c.AllTracks() {
+			if _, err := t.GetStartIndex(); err != nil {
+				report(t.TrackNumber, "track without INDEX 01 is dropped")
+			}
+			if t.IsDataTrack() {
+				report(t.TrackNumber, "data track is dropped")
+			}
+		}
+	}
+	return issues
+}
+
+// firstTrackNumber returns the number of the first track, or zero if there is none
+func (c *Cuesheet) firstTrackNumber() uint {
+	if first := c.TrackAt(0); first != nil {
+		return first.TrackNumber
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package cuesheet
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	input := "FILE \"image.wav\" WAVE\n" +
+		"  TRACK 01 AUDIO\n    INDEX 00 00:00:00\n    INDEX 01 00:30:00\n" +
+		"  TRACK 02 AUDIO\n    PREGAP 00:02:00\n    INDEX 00 03:58:00\n    INDEX 01 04:00:00\n" +
+		"  TRACK 03 MODE1/2352\n    INDEX 01 08:00:00\n    POSTGAP 00:02:00\n" +
+		"FILE \"bonus.wav\" WAVE\n  TRACK 04 AUDIO\n    INDEX 00 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		player   Player
+		expected []string
+	}{
+		{PlayerFoobar2000, []string{
+			"foobar2000: hidden track before INDEX 01 of the first track is not playable",
+			"foobar2000: track 02: PREGAP silence is ignored",
+			"foobar2000: track 03: POSTGAP silence is ignored",
+		}},
+		{PlayerVLC, []string{
+			"VLC: multi-file sheets may only play the first FILE",
+			"VLC: track 02: INDEX 00 pregap is played at the start of this track",
+			"VLC: track 04: INDEX 00 pregap is played at the start of this track",
+		}},
+		{PlayerCmus, []string{
+			"cmus: multi-file sheets are not supported",
+			"cmus: track 03: data track is dropped",
+			"cmus: track 04: track without INDEX 01 is dropped",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.player.String(), func(t *testing.T) {
+			var got []string
+			for _, issue := range cuesheet.CheckCompatibility(tt.player) {
+				got = append(got, issue.String())
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(tt.expected, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+
+	file, err := os.Open("testdata/sample_1.cue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	simple, err := ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, player := range []Player{PlayerFoobar2000, PlayerVLC, PlayerCmus} {
+		if issues := simple.CheckCompatibility(player); len(issues) != 0 {
+			t.Errorf("expected sample_1 to suit %s, got: %v", player, issues)
+		}
+	}
+}
@@ -2,19 +2,45 @@ package cuesheet
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 const (
-	delims          = "\t\n\r "
-	eol             = "\n"
-	framesPerSecond = 75
+	delims = "\t\n\r "
+	eol    = "\n"
 )
 
+// FramesPerSecond is the number of frames in one second of CD audio
+const FramesPerSecond = 75
+
+// FrameScale is the number of frames per second used to convert MSF times
+// CUE sheets for CD always use CDFrameScale; other rates are for sheets that
+// describe other media
+type FrameScale uint64
+
+// CDFrameScale is the Red Book CD frame rate
+const CDFrameScale FrameScale = FramesPerSecond
+
+// orCD returns the scale, or CDFrameScale if it is zero
+func (fs FrameScale) orCD() FrameScale {
+	if fs == 0 {
+		return CDFrameScale
+	}
+	return fs
+}
+
 // Frame represents CD audio time in frames
 // The CD standard uses 75 frames per second
 // Time format: MSF (Minutes:Seconds:Frames) e.g., "03:45:22" = 3 minutes, 45 seconds, 22 frames
@@ -35,13 +61,24 @@ const (
 	RemReplayGainAlbumPeak
 	RemReplayGainTrackGain
 	RemReplayGainTrackPeak
+	RemReplayGainReferenceLoudness
+	RemReplayGainAlbumRange
+	RemReplayGainTrackRange
+	RemReplayGainOther // Any other REPLAYGAIN_* key
 )
 
+// IsReplayGain returns true for any ReplayGain REM type
+func (t RemType) IsReplayGain() bool {
+	return t >= RemReplayGainAlbumGain && t <= RemReplayGainTrackPeak ||
+		t >= RemReplayGainReferenceLoudness && t <= RemReplayGainOther
+}
+
 // RemField represents a parsed REM comment field
 type RemField struct {
 	Type  RemType
 	Key   string
 	Value string
+	Raw   string // value text exactly as written, quotes included
 }
 
 const (
@@ -58,11 +95,13 @@ const (
 // INDEX 02+: Sub-indexes within the track (optional)
 //
 // Example:
-//   INDEX 00 03:00:00  - Pregap starts at 3 minutes
-//   INDEX 01 03:02:00  - Track starts at 3:02 (with 2 second pregap)
+//
+//	INDEX 00 03:00:00  - Pregap starts at 3 minutes
+//	INDEX 01 03:02:00  - Track starts at 3:02 (with 2 second pregap)
 type TrackIndex struct {
-	Number uint  // Index number (0-99, where 0=pregap, 1=track start)
-	Frame  Frame // Position in MSF time format
+	Number      uint  // Index number (0-99, where 0=pregap, 1=track start)
+	Frame       Frame // Position in MSF time format
+	NumberWidth int   // Digits of Number in the source, kept with PreserveFormatting
 }
 
 type Track struct {
@@ -79,6 +118,9 @@ type Track struct {
 	Pregap        Frame
 	Postgap       Frame
 	Index         []TrackIndex
+	Comment       string   // Nonstandard trailing text after the TRACK data type, kept verbatim
+	Rem           []string // Track-level REM comments, e.g. REPLAYGAIN_TRACK_GAIN
+	NumberWidth   int      // Digits of TrackNumber in the source, kept with PreserveFormatting
 }
 
 type File struct {
@@ -103,146 +145,495 @@ type Cuesheet struct {
 	Pregap     Frame
 	Postgap    Frame
 	File       []File
+	Unknown    []RawLine // Unrecognized commands, kept for round-trips
+}
+
+// RawLine is a line the parser did not recognize, such as a vendor-specific
+// directive, kept so WriteFile can emit it again
+// A line nested in a TRACK is written back after its FILE block
+type RawLine struct {
+	Text  string // Line as written, without indentation or line ending
+	After int    // Number of FILE entries before the line; 0 is the header
+}
+
+// ReadOptions controls how ReadFileWithOptions parses a cuesheet
+type ReadOptions struct {
+	// UnquotedText makes unquoted CD-TEXT values (TITLE, PERFORMER, SONGWRITER,
+	// COMPOSER, ARRANGER, MESSAGE, GENRE) take the rest of the line, so
+	// "TITLE Some Album" yields "Some Album" instead of "Some"
+	UnquotedText bool
+
+	// SkipPreamble recovers sheets with a non-standard header by ignoring
+	// every line before the first top-level CUE command (REM, CATALOG,
+	// CDTEXTFILE, TITLE, PERFORMER, SONGWRITER or FILE)
+	SkipPreamble bool
+
+	// Warn, if set, receives a message for each recovery the reader makes
+	Warn func(msg string)
+
+	// FrameScale is the frame rate of MSF times; zero means CDFrameScale
+	FrameScale FrameScale
+
+	// JoinContinuations joins a line ending in a backslash with the next one,
+	// recovering long CD-TEXT values wrapped by some tools; the backslash,
+	// the line break and the next line's indentation are removed
+	JoinContinuations bool
+
+	// JoinQuotedLines continues a double-quoted value left open at the end of
+	// a line onto the following lines until the closing quote, recovering
+	// long titles some tools wrap. The pieces are joined with
+	// QuotedLineSeparator after trimming the indentation of each new line
+	// Joining stops, with a message to Warn, at a line starting with a CUE
+	// command or at the end of the input, so a stray quote cannot swallow
+	// the rest of the sheet
+	JoinQuotedLines bool
+
+	// QuotedLineSeparator joins the lines of a value split by JoinQuotedLines,
+	// e.g. "\n" to keep the line break; empty means a single space
+	QuotedLineSeparator string
+
+	// PreserveFormatting records the digit count of TRACK and INDEX numbers
+	// (e.g. "INDEX 1") in NumberWidth so it can be written back unchanged
+	PreserveFormatting bool
+
+	// NormalizeISRC rewrites ISRC values written with hyphens, spaces or
+	// lowercase letters in the canonical 12-character form; values that do
+	// not form a valid ISRC are kept as written. Ignored in Strict mode
+	NormalizeISRC bool
+
+	// Strict rejects malformed input instead of recovering: unknown commands,
+	// a TRACK before any FILE, tracks without INDEX 01, index numbers above 99
+	// and FILE types not in ValidFileTypes fail with a ParseError
+	Strict bool
+}
+
+// preambleEnd lists the commands that end a preamble skipped by SkipPreamble
+var preambleEnd = map[string]bool{
+	"REM": true, "CATALOG": true, "CDTEXTFILE": true, "TITLE": true,
+	"PERFORMER": true, "SONGWRITER": true, "FILE": true,
+}
+
+// readNumber reads a TRACK or INDEX number, recording its digit count
+// in width when PreserveFormatting is set
+func (o *ReadOptions) readNumber(s *string, width *int) (uint, error) {
+	v := ReadString(s)
+	n, err := strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if o.PreserveFormatting {
+		*width = len(v)
+	}
+	return uint(n), nil
+}
+
+// warnf reports a recovery through Warn, if set
+func (o *ReadOptions) warnf(format string, args ...any) {
+	if o.Warn != nil {
+		o.Warn(fmt.Sprintf(format, args...))
+	}
+}
+
+// readText reads a CD-TEXT value, honoring UnquotedText
+func (o *ReadOptions) readText(s *string) string {
+	if o.UnquotedText {
+		*s = strings.TrimLeft(*s, delims)
+		if !isQuoted(*s) {
+			v := *s
+			*s = ""
+			return v
+		}
+	}
+	return ReadString(s)
 }
 
 func ReadFile(r io.Reader) (*Cuesheet, error) {
-	b := bufio.NewReader(r)
+	return ReadFileWithOptions(r, ReadOptions{})
+}
+
+// Parse reads a cuesheet from data; a leading UTF-8 byte order mark is skipped
+func Parse(data []byte) (*Cuesheet, error) {
+	return ReadFile(bytes.NewReader(data))
+}
+
+// ParseString reads a cuesheet from s; a leading UTF-8 byte order mark is skipped
+func ParseString(s string) (*Cuesheet, error) {
+	return ReadFile(strings.NewReader(s))
+}
+
+// ReadFileStrict reads a cuesheet, failing on input ReadFile would recover
+// from; see ReadOptions.Strict
+func ReadFileStrict(r io.Reader) (*Cuesheet, error) {
+	return ReadFileWithOptions(r, ReadOptions{Strict: true})
+}
+
+// ReadFileWithOptions reads a cuesheet using the given options
+func ReadFileWithOptions(r io.Reader, opts ReadOptions) (*Cuesheet, error) {
 	cuesheet := &Cuesheet{}
+	var track *Track
+
+	err := ParseStreamWithOptions(r, opts, func(ev Event) error {
+		switch ev.Kind {
+		case EventHeader:
+			cuesheet.setHeader(ev)
+		case EventFile:
+			ev.File.Tracks = []Track{}
+			cuesheet.File = append(cuesheet.File, ev.File)
+		case EventTrack:
+			f := &cuesheet.File[len(cuesheet.File)-1]
+			f.Tracks = append(f.Tracks, ev.Track)
+			track = &f.Tracks[len(f.Tracks)-1]
+		case EventTrackField:
+			track.setField(ev)
+		case EventIndex:
+			track.Index = append(track.Index, ev.Index)
+		case EventUnknown:
+			cuesheet.Unknown = append(cuesheet.Unknown, RawLine{ev.Value, len(cuesheet.File)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cuesheet, nil
+}
+
+// setHeader stores an album-level field from a header event
+func (c *Cuesheet) setHeader(ev Event) {
+	switch ev.Command {
+	case "REM":
+		c.Rem = append(c.Rem, ev.Value)
+	case "CATALOG":
+		c.Catalog = ev.Value
+	case "CDTEXTFILE":
+		c.CdTextFile = ev.Value
+	case "TITLE":
+		c.Title = ev.Value
+	case "PERFORMER":
+		c.Performer = ev.Value
+	case "SONGWRITER":
+		c.SongWriter = ev.Value
+	case "COMPOSER":
+		c.Composer = ev.Value
+	case "ARRANGER":
+		c.Arranger = ev.Value
+	case "MESSAGE":
+		c.Message = ev.Value
+	case "GENRE":
+		c.Genre = ev.Value
+	case "DISC_ID":
+		c.DiscId = ev.Value
+	case "UPC_EAN":
+		c.UpcEan = ev.Value
+	case "PREGAP":
+		c.Pregap = ev.Frame
+	case "POSTGAP":
+		c.Postgap = ev.Frame
+	}
+}
+
+// setField stores a track-level field from a track field event
+func (t *Track) setField(ev Event) {
+	switch ev.Command {
+	case "FLAGS":
+		t.Flags = ev.Flags
+	case "ISRC":
+		t.Isrc = ev.Value
+	case "TITLE":
+		t.Title = ev.Value
+	case "PERFORMER":
+		t.Performer = ev.Value
+	case "SONGWRITER":
+		t.SongWriter = ev.Value
+	case "COMPOSER":
+		t.Composer = ev.Value
+	case "ARRANGER":
+		t.Arranger = ev.Value
+	case "MESSAGE":
+		t.Message = ev.Value
+	case "PREGAP":
+		t.Pregap = ev.Frame
+	case "POSTGAP":
+		t.Postgap = ev.Frame
+	case "REM":
+		t.Rem = append(t.Rem, ev.Value)
+	}
+}
+
+// ParseStream parses a cuesheet and calls handler for each element as it is
+// read, without building a Cuesheet, so very large sheets can be processed
+// incrementally. A non-nil error from handler stops parsing and is returned
+func ParseStream(r io.Reader, handler func(ev Event) error) error {
+	return ParseStreamWithOptions(r, ReadOptions{}, handler)
+}
+
+// ParseStreamWithOptions parses a cuesheet as a stream of events using the given options
+// A UTF-8 byte order mark is skipped; input starting with a UTF-16 one fails with ErrUTF16
+func ParseStreamWithOptions(r io.Reader, opts ReadOptions, handler func(ev Event) error) error {
+	b := &lineReader{b: bufio.NewReader(r), joinContinuations: opts.JoinContinuations, warnf: opts.warnf}
+	if opts.JoinQuotedLines {
+		b.joinQuotes = true
+		b.quoteSeparator = opts.QuotedLineSeparator
+		if b.quoteSeparator == "" {
+			b.quoteSeparator = " "
+		}
+	}
+	if bom, _ := b.b.Peek(2); len(bom) == 2 && (bom[0] == 0xff && bom[1] == 0xfe || bom[0] == 0xfe && bom[1] == 0xff) {
+		return ErrUTF16
+	}
+	inPreamble := opts.SkipPreamble
+	inFile := false
+	skipped := 0
 
 	for {
-		line, err := (*b).ReadString('\n')
+		line, err := b.next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return err
 		}
+		raw := line
 		line = strings.Trim(line, delims)
 		command := ReadString(&line)
 
-		switch command {
-		case "REM":
-			cuesheet.Rem = append(cuesheet.Rem, line)
-		case "CATALOG":
-			cuesheet.Catalog = line
-		case "CDTEXTFILE":
-			cuesheet.CdTextFile = ReadString(&line)
-		case "TITLE":
-			cuesheet.Title = ReadString(&line)
-		case "PERFORMER":
-			cuesheet.Performer = ReadString(&line)
-		case "SONGWRITER":
-			cuesheet.SongWriter = ReadString(&line)
-		case "COMPOSER":
-			cuesheet.Composer = ReadString(&line)
-		case "ARRANGER":
-			cuesheet.Arranger = ReadString(&line)
-		case "MESSAGE":
-			cuesheet.Message = ReadString(&line)
-		case "GENRE":
-			cuesheet.Genre = ReadString(&line)
-		case "DISC_ID":
-			cuesheet.DiscId = ReadString(&line)
-		case "UPC_EAN":
-			cuesheet.UpcEan = ReadString(&line)
-		case "PREGAP":
-			frame, err := ReadFrame(&line)
-			if err != nil {
-				return nil, err
+		if inPreamble {
+			if !preambleEnd[command] {
+				skipped++
+				continue
 			}
-			cuesheet.Pregap = frame
-		case "POSTGAP":
-			frame, err := ReadFrame(&line)
+			inPreamble = false
+			if skipped > 0 {
+				opts.warnf("skipped %d line(s) before the first CUE command", skipped)
+			}
+		}
+
+		ev := Event{Kind: EventHeader, Line: b.lineNumber, Command: command}
+		switch command {
+		case "REM", "CATALOG":
+			ev.Value = line
+		case "CDTEXTFILE", "DISC_ID", "UPC_EAN":
+			ev.Value = ReadString(&line)
+		case "TITLE", "PERFORMER", "SONGWRITER", "COMPOSER", "ARRANGER", "MESSAGE", "GENRE":
+			ev.Value = opts.readText(&line)
+		case "PREGAP", "POSTGAP":
+			frame, err := opts.FrameScale.ReadFrame(&line)
 			if err != nil {
-				return nil, err
+				return b.parseError(command, err)
 			}
-			cuesheet.Postgap = frame
+			ev.Frame = frame
 		case "FILE":
-			fname := ReadString(&line)
-			ftype := ReadString(&line)
-			tracks, err := readTracks(b)
-			if err != nil {
-				return nil, err
+			ev.Kind = EventFile
+			ev.File.FileName = ReadString(&line)
+			ev.File.FileType = ReadString(&line)
+			if opts.Strict {
+				if err := ValidateFileType(ev.File.FileType); err != nil {
+					return b.parseError(command, ErrInvalidFileType)
+				}
+			}
+			if err := handler(ev); err != nil {
+				return err
+			}
+			inFile = true
+			if err := readTracks(b, &opts, handler); err != nil {
+				return err
 			}
-			cuesheet.File = append(cuesheet.File, File{fname, ftype, *tracks})
+			continue
+		case "TRACK":
+			// A track separated from its FILE by an unknown command
+			if inFile {
+				b.unread(raw)
+				if err := readTracks(b, &opts, handler); err != nil {
+					return err
+				}
+			} else if opts.Strict {
+				return b.parseError(command, ErrTrackWithoutFile)
+			}
+			continue
+		case "":
+			continue
+		default:
+			if opts.Strict {
+				return b.parseError(command, ErrUnknownCommand)
+			}
+			ev.Kind = EventUnknown
+			ev.Command = ""
+			ev.Value = strings.Trim(raw, delims)
+		}
+		if err := handler(ev); err != nil {
+			return err
 		}
 	}
 
-	return cuesheet, nil
+	if inPreamble && skipped > 0 {
+		opts.warnf("skipped %d line(s) without finding a CUE command", skipped)
+	}
+
+	return nil
+}
+
+// WriteOptions controls how WriteFileWithOptions serializes a cuesheet
+type WriteOptions struct {
+	// MaxFieldLength truncates CD-TEXT fields (TITLE, PERFORMER, SONGWRITER,
+	// COMPOSER, ARRANGER, MESSAGE, GENRE) to at most this many characters.
+	// Zero disables truncation.
+	MaxFieldLength int
+
+	// FrameScale is the frame rate of MSF times; zero means CDFrameScale
+	FrameScale FrameScale
+
+	// LineEnding ends every line, e.g. "\r\n" for Windows tools; empty means "\n"
+	LineEnding string
+
+	// TrackIndent prefixes TRACK lines; empty means two spaces
+	TrackIndent string
+
+	// FieldIndent prefixes the commands inside a track; empty means four spaces
+	FieldIndent string
+
+	// Unindented writes every line without indentation, overriding
+	// TrackIndent and FieldIndent
+	Unindented bool
+
+	// PreserveFormatting writes TRACK and INDEX numbers with the digit count
+	// recorded in NumberWidth instead of always using two digits
+	PreserveFormatting bool
+}
+
+// formatNumber formats a TRACK or INDEX number, honoring a recorded width
+func (o *WriteOptions) formatNumber(n uint, width int) string {
+	if o.PreserveFormatting && width > 0 {
+		return leftPad(strconv.FormatUint(uint64(n), 10), "0", width)
+	}
+	return FormatTrackNumber(n)
+}
+
+// indents returns the TRACK and track field prefixes
+func (o *WriteOptions) indents() (string, string) {
+	if o.Unindented {
+		return "", ""
+	}
+	trackIndent, fieldIndent := o.TrackIndent, o.FieldIndent
+	if trackIndent == "" {
+		trackIndent = "  "
+	}
+	if fieldIndent == "" {
+		fieldIndent = "    "
+	}
+	return trackIndent, fieldIndent
+}
+
+// lineEnding returns LineEnding, or "\n" if it is empty
+func (o *WriteOptions) lineEnding() string {
+	if o.LineEnding == "" {
+		return eol
+	}
+	return o.LineEnding
+}
+
+// truncate shortens a CD-TEXT value to MaxFieldLength characters
+func (o *WriteOptions) truncate(s string) string {
+	if o.MaxFieldLength <= 0 {
+		return s
+	}
+	return truncateRunes(s, o.MaxFieldLength)
 }
 
 func WriteFile(w io.Writer, cuesheet *Cuesheet) error {
-	ws := bufio.NewWriter(w)
+	return WriteFileWithOptions(w, cuesheet, WriteOptions{})
+}
+
+// WriteFileWithOptions writes the cuesheet using the given options
+// Every line ends with a single LineEnding; an empty cuesheet is written as one
+// empty line so the output is never zero bytes
+func WriteFileWithOptions(w io.Writer, cuesheet *Cuesheet, opts WriteOptions) error {
+	nl := opts.lineEnding()
+	trackIndent, fieldIndent := opts.indents()
+	var ws bytes.Buffer
 
 	for i := 0; i < len(cuesheet.Rem); i++ {
-		ws.WriteString("REM " + cuesheet.Rem[i] + eol)
+		ws.WriteString("REM " + strings.TrimRight(cuesheet.Rem[i], "\r\n") + nl)
 	}
 
 	if len(cuesheet.Catalog) > 0 {
-		ws.WriteString("CATALOG " + cuesheet.Catalog + eol)
+		ws.WriteString("CATALOG " + cuesheet.Catalog + nl)
 	}
 
 	if len(cuesheet.CdTextFile) > 0 {
-		ws.WriteString("CDTEXTFILE " + FormatString(cuesheet.CdTextFile) + eol)
+		ws.WriteString("CDTEXTFILE " + FormatString(cuesheet.CdTextFile) + nl)
 	}
 
 	if len(cuesheet.Title) > 0 {
-		ws.WriteString("TITLE " + FormatString(cuesheet.Title) + eol)
+		ws.WriteString("TITLE " + FormatString(opts.truncate(cuesheet.Title)) + nl)
 	}
 
 	if len(cuesheet.Performer) > 0 {
-		ws.WriteString("PERFORMER " + FormatString(cuesheet.Performer) + eol)
+		ws.WriteString("PERFORMER " + FormatString(opts.truncate(cuesheet.Performer)) + nl)
 	}
 
 	if len(cuesheet.SongWriter) > 0 {
-		ws.WriteString("SONGWRITER " + FormatString(cuesheet.SongWriter) + eol)
+		ws.WriteString("SONGWRITER " + FormatString(opts.truncate(cuesheet.SongWriter)) + nl)
 	}
 
 	if len(cuesheet.Composer) > 0 {
-		ws.WriteString("COMPOSER " + FormatString(cuesheet.Composer) + eol)
+		ws.WriteString("COMPOSER " + FormatString(opts.truncate(cuesheet.Composer)) + nl)
 	}
 
 	if len(cuesheet.Arranger) > 0 {
-		ws.WriteString("ARRANGER " + FormatString(cuesheet.Arranger) + eol)
+		ws.WriteString("ARRANGER " + FormatString(opts.truncate(cuesheet.Arranger)) + nl)
 	}
 
 	if len(cuesheet.Message) > 0 {
-		ws.WriteString("MESSAGE " + FormatString(cuesheet.Message) + eol)
+		ws.WriteString("MESSAGE " + FormatString(opts.truncate(cuesheet.Message)) + nl)
 	}
 
 	if len(cuesheet.Genre) > 0 {
-		ws.WriteString("GENRE " + FormatString(cuesheet.Genre) + eol)
+		ws.WriteString("GENRE " + FormatString(opts.truncate(cuesheet.Genre)) + nl)
 	}
 
 	if len(cuesheet.DiscId) > 0 {
-		ws.WriteString("DISC_ID " + FormatString(cuesheet.DiscId) + eol)
+		ws.WriteString("DISC_ID " + FormatString(cuesheet.DiscId) + nl)
 	}
 
 	if len(cuesheet.UpcEan) > 0 {
-		ws.WriteString("UPC_EAN " + FormatString(cuesheet.UpcEan) + eol)
+		ws.WriteString("UPC_EAN " + FormatString(cuesheet.UpcEan) + nl)
 	}
 
 	if cuesheet.Pregap > 0 {
-		ws.WriteString("PREGAP " + FormatFrame(cuesheet.Pregap) + eol)
+		ws.WriteString("PREGAP " + opts.FrameScale.FormatFrame(cuesheet.Pregap) + nl)
 	}
 
 	if cuesheet.Postgap > 0 {
-		ws.WriteString("POSTGAP " + FormatFrame(cuesheet.Postgap) + eol)
+		ws.WriteString("POSTGAP " + opts.FrameScale.FormatFrame(cuesheet.Postgap) + nl)
+	}
+
+	// Lines placed after a FILE that no longer exists go after the last one
+	writeUnknown := func(after int) {
+		for _, line := range cuesheet.Unknown {
+			if line.After == after || after == len(cuesheet.File) && line.After > after {
+				ws.WriteString(strings.TrimRight(line.Text, "\r\n") + nl)
+			}
+		}
 	}
+	writeUnknown(0)
 
 	for i := 0; i < len(cuesheet.File); i++ {
 		file := cuesheet.File[i]
 		ws.WriteString("FILE " + FormatString(file.FileName) +
-			" " + file.FileType + eol)
+			" " + FormatString(file.FileType) + nl)
 
 		for i := 0; i < len(file.Tracks); i++ {
 			track := file.Tracks[i]
 
-			ws.WriteString("  TRACK " + FormatTrackNumber(track.TrackNumber) +
-				" " + track.TrackDataType + eol)
+			ws.WriteString(trackIndent + "TRACK " + opts.formatNumber(track.TrackNumber, track.NumberWidth) +
+				" " + FormatString(track.TrackDataType))
+			if len(track.Comment) > 0 {
+				ws.WriteString(" " + track.Comment)
+			}
+			ws.WriteString(nl)
 
 			if track.Flags != None {
-				ws.WriteString("    FLAGS")
+				ws.WriteString(fieldIndent + "FLAGS")
 				if (track.Flags & Dcp) != 0 {
 					ws.WriteString(" DCP")
 				}
@@ -255,63 +646,77 @@ func WriteFile(w io.Writer, cuesheet *Cuesheet) error {
 				if (track.Flags & Scms) != 0 {
 					ws.WriteString(" SCMS")
 				}
-				ws.WriteString(eol)
+				ws.WriteString(nl)
 			}
 
 			if len(track.Isrc) > 0 {
-				ws.WriteString("    ISRC " + track.Isrc + eol)
+				ws.WriteString(fieldIndent + "ISRC " + track.Isrc + nl)
 			}
 
 			if len(track.Title) > 0 {
-				ws.WriteString("    TITLE " + FormatString(track.Title) + eol)
+				ws.WriteString(fieldIndent + "TITLE " + FormatString(opts.truncate(track.Title)) + nl)
 			}
 
 			if len(track.Performer) > 0 {
-				ws.WriteString("    PERFORMER " + FormatString(track.Performer) + eol)
+				ws.WriteString(fieldIndent + "PERFORMER " + FormatString(opts.truncate(track.Performer)) + nl)
 			}
 
 			if len(track.SongWriter) > 0 {
-				ws.WriteString("    SONGWRITER " + FormatString(track.SongWriter) + eol)
+				ws.WriteString(fieldIndent + "SONGWRITER " + FormatString(opts.truncate(track.SongWriter)) + nl)
 			}
 
 			if len(track.Composer) > 0 {
-				ws.WriteString("    COMPOSER " + FormatString(track.Composer) + eol)
+				ws.WriteString(fieldIndent + "COMPOSER " + FormatString(opts.truncate(track.Composer)) + nl)
 			}
 
 			if len(track.Arranger) > 0 {
-				ws.WriteString("    ARRANGER " + FormatString(track.Arranger) + eol)
+				ws.WriteString(fieldIndent + "ARRANGER " + FormatString(opts.truncate(track.Arranger)) + nl)
 			}
 
 			if len(track.Message) > 0 {
-				ws.WriteString("    MESSAGE " + FormatString(track.Message) + eol)
+				ws.WriteString(fieldIndent + "MESSAGE " + FormatString(opts.truncate(track.Message)) + nl)
 			}
 
-			if track.Pregap > 0 {
-				ws.WriteString("    PREGAP " + FormatFrame(track.Pregap) + eol)
+			for _, rem := range track.Rem {
+				ws.WriteString(fieldIndent + "REM " + strings.TrimRight(rem, "\r\n") + nl)
 			}
 
-			if track.Postgap > 0 {
-				ws.WriteString("    POSTGAP " + FormatFrame(track.Postgap) + eol)
+			if track.Pregap > 0 {
+				ws.WriteString(fieldIndent + "PREGAP " + opts.FrameScale.FormatFrame(track.Pregap) + nl)
 			}
 
 			for i := 0; i < len(track.Index); i++ {
 				index := track.Index[i]
-				ws.WriteString("    INDEX " + FormatTrackNumber(index.Number) +
-					" " + FormatFrame(index.Frame) + eol)
+				ws.WriteString(fieldIndent + "INDEX " + opts.formatNumber(index.Number, index.NumberWidth) +
+					" " + opts.FrameScale.FormatFrame(index.Frame) + nl)
+			}
+
+			// Per the spec POSTGAP follows the last INDEX
+			if track.Postgap > 0 {
+				ws.WriteString(fieldIndent + "POSTGAP " + opts.FrameScale.FormatFrame(track.Postgap) + nl)
 			}
 		}
+
+		writeUnknown(i + 1)
 	}
 
-	ws.Flush()
+	if ws.Len() == 0 {
+		ws.WriteString(nl)
+	}
 
-	return nil
+	_, err := ws.WriteTo(w)
+	return err
 }
 
+// ReadString reads the next quoted or space-separated token from s and
+// advances s past it. A quoted token is skipped by the number of source bytes
+// unquote consumed, not the length of its unescaped value, so a token that
+// follows one with escapes (e.g. the type in FILE "a\"b.wav" WAVE) is read intact
 func ReadString(s *string) string {
 	*s = strings.TrimLeft(*s, delims)
 	if isQuoted(*s) {
-		v := unquote(*s)
-		*s = (*s)[len(v)+2:]
+		v, n := unquote(*s)
+		*s = (*s)[n:]
 		return v
 	}
 	for i := 0; i < len(*s); i++ {
@@ -345,6 +750,11 @@ func ReadUint(s *string) (uint, error) {
 }
 
 func ReadFrame(s *string) (Frame, error) {
+	return CDFrameScale.ReadFrame(s)
+}
+
+// ReadFrame reads an MM:SS:FF time where FF counts frames at this scale
+func (fs FrameScale) ReadFrame(s *string) (Frame, error) {
 	v := strings.Split(ReadString(s), ":")
 	if len(v) != 3 {
 		return 0, strconv.ErrSyntax
@@ -361,11 +771,11 @@ func ReadFrame(s *string) (Frame, error) {
 	if err != nil {
 		return 0, err
 	}
-	return Frame((mm*60+ss)*framesPerSecond + ff), nil
+	return Frame((mm*60+ss)*uint64(fs.orCD()) + ff), nil
 }
 
 func FormatString(s string) string {
-	if strings.ContainsAny(s, delims) {
+	if s == "" || strings.ContainsAny(s, delims) || isQuoted(s) {
 		return quote(s, '"')
 	}
 	return s
@@ -376,10 +786,16 @@ func FormatTrackNumber(n uint) string {
 }
 
 func FormatFrame(frame Frame) string {
-	n := frame / framesPerSecond
+	return CDFrameScale.FormatFrame(frame)
+}
+
+// FormatFrame formats a frame position as MM:SS:FF at this scale
+func (fs FrameScale) FormatFrame(frame Frame) string {
+	rate := Frame(fs.orCD())
+	n := frame / rate
 	mm := n / 60
 	ss := n % 60
-	ff := frame % framesPerSecond
+	ff := frame % rate
 	return leftPad(strconv.FormatUint(uint64(mm), 10), "0", 2) + ":" +
 		leftPad(strconv.FormatUint(uint64(ss), 10), "0", 2) + ":" +
 		leftPad(strconv.FormatUint(uint64(ff), 10), "0", 2)
@@ -397,7 +813,7 @@ func quote(s string, quote byte) string {
 	buf = append(buf, quote)
 	for i := 0; i < len(s); i++ {
 		c := s[i]
-		if c == quote || c == '\\' {
+		if c == quote || (c == '\\' && isEscapable(s, i+1, quote)) {
 			buf = append(buf, '\\')
 			buf = append(buf, byte(c))
 		} else {
@@ -408,144 +824,346 @@ func quote(s string, quote byte) string {
 	return string(buf)
 }
 
-func unquote(s string) string {
+// isEscapable reports whether a backslash before s[i] must itself be escaped
+// Other backslashes (e.g. in Windows paths) are written and read literally
+func isEscapable(s string, i int, quote byte) bool {
+	return i >= len(s) || s[i] == quote || s[i] == '\\'
+}
+
+// unquote returns the unescaped value of the quoted string at the start of s
+// and the number of bytes it occupies, including the quotes
+// An unterminated quote runs to the end of s
+func unquote(s string) (string, int) {
 	quote := s[0]
-	i := 1
-	for ; i < len(s); i++ {
-		if s[i] == quote {
-			break
+	buf := make([]byte, 0, len(s))
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == quote {
+			return string(buf), i + 1
 		}
-		if s[i] == '\\' {
+		if c == '\\' && i+1 < len(s) && (s[i+1] == quote || s[i+1] == '\\') {
 			i++
+			c = s[i]
+		}
+		buf = append(buf, c)
+	}
+	return string(buf), len(s)
+}
+
+// ParseError reports where in the input a cuesheet failed to parse
+type ParseError struct {
+	Line    int    // 1-based line number
+	Column  int    // 1-based column where the command starts
+	Command string // CUE command being parsed, e.g. "INDEX"
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	return "cue parse error at line " + strconv.Itoa(e.Line) + ": " + e.Command + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// ErrUnknownCommand reports a command the strict reader does not recognize
+	ErrUnknownCommand = errors.New("unknown command")
+	// ErrMissingStartIndex reports a track without INDEX 01 in strict mode
+	ErrMissingStartIndex = errors.New("track has no INDEX 01")
+	// ErrInvalidFileType reports a FILE type not in ValidFileTypes in strict mode
+	ErrInvalidFileType = errors.New("invalid file type")
+	// ErrTrackWithoutFile reports a TRACK before any FILE in strict mode
+	ErrTrackWithoutFile = errors.New("TRACK before any FILE")
+	// ErrUTF16 reports input starting with a UTF-16 byte order mark
+	ErrUTF16 = errors.New("UTF-16 input is not supported, convert the cuesheet to UTF-8")
+)
+
+// lineReader reads input line by line with one line of lookahead
+type lineReader struct {
+	b          *bufio.Reader
+	pending    string
+	hasPending bool
+	lineNumber int    // number of the line last returned by next
+	current    string // line last returned by next
+
+	joinContinuations bool // join lines ending in a backslash with the next
+	joined            int  // continuation lines folded into the last line read
+
+	joinQuotes     bool   // continue a double-quoted value left open at the end of a line
+	quoteSeparator string // joins the lines of a continued quoted value
+	held           string // line read ahead while joining, returned before new input
+	hasHeld        bool
+	warnf          func(format string, args ...any)
+}
+
+// parseError wraps err with the position of the current line
+func (r *lineReader) parseError(command string, err error) error {
+	column := len(r.current) - len(strings.TrimLeft(r.current, delims)) + 1
+	return &ParseError{Line: r.lineNumber, Column: column, Command: command, Err: err}
+}
+
+// next returns the next line including its terminator
+// A final line without a newline is returned with a nil error
+// A UTF-8 byte order mark is stripped from the start of every line, since
+// files concatenated by some tools carry one in the middle
+func (r *lineReader) next() (string, error) {
+	if r.hasPending {
+		r.hasPending = false
+		r.lineNumber++
+		r.current = r.pending
+		return r.pending, nil
+	}
+	line, err := r.readRaw()
+	line = strings.TrimPrefix(line, "\ufeff")
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err == nil {
+		r.lineNumber += 1 + r.joined
+		r.joined = 0
+		for r.joinContinuations && strings.HasSuffix(strings.TrimRight(line, "\r\n"), "\\") {
+			more, err := r.readRaw()
+			if len(more) == 0 || (err != nil && err != io.EOF) {
+				break
+			}
+			r.joined++
+			more = strings.TrimLeft(strings.TrimPrefix(more, "\ufeff"), " \t")
+			line = strings.TrimSuffix(strings.TrimRight(line, "\r\n"), "\\") + more
+		}
+		for r.joinQuotes && hasOpenQuote(line) {
+			more, err := r.readRaw()
+			if len(more) == 0 || (err != nil && err != io.EOF) {
+				r.warnf("line %d: quoted value is not closed before the end of the input", r.lineNumber)
+				break
+			}
+			if startsWithCommand(more) {
+				// A stray quote must not swallow the commands that follow
+				r.held, r.hasHeld = more, true
+				r.warnf("line %d: quoted value is not closed before the next command", r.lineNumber)
+				break
+			}
+			r.joined++
+			more = strings.TrimLeft(strings.TrimPrefix(more, "\ufeff"), " \t")
+			line = strings.TrimRight(line, "\r\n") + r.quoteSeparator + more
+		}
+		r.current = line
+	}
+	return line, err
+}
+
+// readRaw returns the next physical line, or the line held back by next
+func (r *lineReader) readRaw() (string, error) {
+	if r.hasHeld {
+		r.hasHeld = false
+		return r.held, nil
+	}
+	return r.b.ReadString('\n')
+}
+
+// startsWithCommand reports whether line begins with a CUE command
+func startsWithCommand(line string) bool {
+	fields := strings.Fields(strings.TrimPrefix(line, "\ufeff"))
+	return len(fields) > 0 && (trackCommands[fields[0]] || topLevelCommands[fields[0]])
+}
+
+// hasOpenQuote reports whether line ends inside a double-quoted value
+func hasOpenQuote(line string) bool {
+	open := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '"':
+			open = !open
+		case line[i] == '\\' && open && i+1 < len(line) && (line[i+1] == '"' || line[i+1] == '\\'):
+			i++ // escaped quote or backslash, as read by unquote
 		}
 	}
-	return s[1:i]
+	return open
+}
+
+// unread pushes a line back so the next call to next returns it again
+func (r *lineReader) unread(line string) {
+	r.pending = line
+	r.hasPending = true
+	r.lineNumber--
 }
 
-func readTrack(b *bufio.Reader, track *Track) error {
-L:
+// trackCommands are the commands that may appear inside a TRACK block
+var trackCommands = map[string]bool{
+	"FLAGS": true, "ISRC": true, "REM": true, "TITLE": true, "PERFORMER": true,
+	"SONGWRITER": true, "COMPOSER": true, "ARRANGER": true, "MESSAGE": true,
+	"PREGAP": true, "POSTGAP": true, "INDEX": true,
+}
+
+// topLevelCommands are the commands that may appear outside a TRACK block
+var topLevelCommands = map[string]bool{
+	"REM": true, "CATALOG": true, "CDTEXTFILE": true, "TITLE": true, "PERFORMER": true,
+	"SONGWRITER": true, "COMPOSER": true, "ARRANGER": true, "MESSAGE": true, "GENRE": true,
+	"DISC_ID": true, "UPC_EAN": true, "PREGAP": true, "POSTGAP": true, "FILE": true, "TRACK": true,
+}
+
+// indentation returns the length of the leading whitespace run of line
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// readTrack reads the commands of a TRACK block whose TRACK line is indented
+// by trackIndent bytes of any whitespace
+// Nesting is decided by command, so tab-indented and unindented input parses;
+// a command indented less than the TRACK line (e.g. an album REM after the
+// last track of a file) ends the block
+func readTrack(b *lineReader, trackIndent int, opts *ReadOptions, handler func(ev Event) error) error {
 	for {
-		before := *b
-		line, err := (*b).ReadString('\n')
+		line, err := b.next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
 			return err
 		}
-		if !strings.HasPrefix(line, "    ") {
-			*b = before
-			break
-		}
+		raw := line
 		line = strings.Trim(line, delims)
 		command := ReadString(&line)
 
+		if command == "" {
+			continue
+		}
+		if !trackCommands[command] || indentation(raw) < trackIndent {
+			if indentation(raw) > trackIndent && command != "TRACK" && command != "FILE" {
+				// Unknown command nested in the track
+				if opts.Strict {
+					return b.parseError(command, ErrUnknownCommand)
+				}
+				ev := Event{Kind: EventUnknown, Line: b.lineNumber, Value: strings.Trim(raw, delims)}
+				if err := handler(ev); err != nil {
+					return err
+				}
+				continue
+			}
+			b.unread(raw)
+			break
+		}
+
+		ev := Event{Kind: EventTrackField, Line: b.lineNumber, Command: command}
 		switch command {
 		case "FLAGS":
-			track.Flags = None
 			for len(line) > 0 {
 				switch ReadString(&line) {
 				case "DCP":
-					track.Flags |= Dcp
+					ev.Flags |= Dcp
 				case "4CH":
-					track.Flags |= Four_ch
+					ev.Flags |= Four_ch
 				case "PRE":
-					track.Flags |= Pre
+					ev.Flags |= Pre
 				case "SCMS":
-					track.Flags |= Scms
+					ev.Flags |= Scms
 				}
 			}
 		case "ISRC":
-			track.Isrc = line
-		case "TITLE":
-			track.Title = ReadString(&line)
-		case "PERFORMER":
-			track.Performer = ReadString(&line)
-		case "SONGWRITER":
-			track.SongWriter = ReadString(&line)
-		case "COMPOSER":
-			track.Composer = ReadString(&line)
-		case "ARRANGER":
-			track.Arranger = ReadString(&line)
-		case "MESSAGE":
-			track.Message = ReadString(&line)
-		case "PREGAP":
-			frame, err := ReadFrame(&line)
-			if err != nil {
-				return err
+			ev.Value = line
+			if opts.NormalizeISRC && !opts.Strict {
+				if isrc, err := NormalizeISRC(line); err == nil {
+					ev.Value = isrc
+				}
 			}
-			track.Pregap = frame
-		case "POSTGAP":
-			frame, err := ReadFrame(&line)
+		case "REM":
+			ev.Value = line
+		case "TITLE", "PERFORMER", "SONGWRITER", "COMPOSER", "ARRANGER", "MESSAGE":
+			ev.Value = opts.readText(&line)
+		case "PREGAP", "POSTGAP":
+			frame, err := opts.FrameScale.ReadFrame(&line)
 			if err != nil {
-				return err
+				return b.parseError(command, err)
 			}
-			track.Postgap = frame
+			ev.Frame = frame
 		case "INDEX":
-			index := TrackIndex{}
-			num, err := ReadUint(&line)
+			num, err := opts.readNumber(&line, &ev.Index.NumberWidth)
 			if err != nil {
-				return err
+				return b.parseError(command, err)
+			}
+			if opts.Strict && num > 99 {
+				return b.parseError(command, strconv.ErrRange)
 			}
-			index.Number = num
-			frame, err := ReadFrame(&line)
+			frame, err := opts.FrameScale.ReadFrame(&line)
 			if err != nil {
-				return err
+				return b.parseError(command, err)
 			}
-			index.Frame = frame
-			track.Index = append(track.Index, index)
-		case "REM":
-			// ignore comment inside of track
-		default:
-			break L
+			ev.Kind = EventIndex
+			ev.Index.Number = num
+			ev.Index.Frame = frame
+		}
+		if err := handler(ev); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func readTracks(b *bufio.Reader) (*[]Track, error) {
-	tracks := &[]Track{}
-
-L:
+func readTracks(b *lineReader, opts *ReadOptions, handler func(ev Event) error) error {
 	for {
-		before := *b
-		line, err := (*b).ReadString('\n')
+		line, err := b.next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, err
-		}
-		if !strings.HasPrefix(line, "  ") {
-			*b = before
-			break
+			return err
 		}
+		raw := line
 		line = strings.Trim(line, delims)
 		command := ReadString(&line)
 
-		switch command {
-		case "TRACK":
-			track := Track{}
-			num, err := ReadUint(&line)
-			if err != nil {
-				return nil, err
+		if command == "" {
+			continue
+		}
+		if command != "TRACK" {
+			b.unread(raw)
+			break
+		}
+		ev := Event{Kind: EventTrack, Line: b.lineNumber, Command: command}
+		num, err := opts.readNumber(&line, &ev.Track.NumberWidth)
+		if err != nil {
+			return b.parseError(command, err)
+		}
+		ev.Track.TrackNumber = num
+		ev.Track.TrackDataType = ReadString(&line)
+		// Anything after the data type (e.g. "; first track") is not part
+		// of the spec; keep it so it survives a round-trip
+		ev.Track.Comment = strings.TrimLeft(line, delims)
+		if err := handler(ev); err != nil {
+			return err
+		}
+		if !opts.Strict {
+			if err := readTrack(b, indentation(raw), opts, handler); err != nil {
+				return err
 			}
-			track.TrackNumber = num
-			track.TrackDataType = ReadString(&line)
-			if err := readTrack(b, &track); err != nil {
-				return nil, err
+			continue
+		}
+
+		missing := b.parseError(command, ErrMissingStartIndex)
+		hasStart := false
+		err = readTrack(b, indentation(raw), opts, func(ev Event) error {
+			if ev.Kind == EventIndex && ev.Index.Number == 1 {
+				hasStart = true
 			}
-			*tracks = append(*tracks, track)
-		default:
-			break L
+			return handler(ev)
+		})
+		if err != nil {
+			return err
+		}
+		if !hasStart {
+			return missing
 		}
 	}
 
-	return tracks, nil
+	return nil
 }
 
 func leftPad(s, padStr string, overallLen int) string {
+	// Never truncate values already wider than the pad width (e.g. track 100)
+	if len(s) >= overallLen {
+		return s
+	}
 	var padCountInt int
 	padCountInt = 1 + ((overallLen - len(padStr)) / len(padStr))
 	var retStr = strings.Repeat(padStr, padCountInt) + s
@@ -556,11 +1174,12 @@ func leftPad(s, padStr string, overallLen int) string {
 
 // ParseRemComment parses a REM comment line into a structured RemField
 // Common formats:
-//   REM DATE "2024"
-//   REM GENRE "Rock"
-//   REM DISCNUMBER 1
-//   REM COMMENT "Text"
-//   REM REPLAYGAIN_ALBUM_GAIN -6.2 dB
+//
+//	REM DATE "2024"
+//	REM GENRE "Rock"
+//	REM DISCNUMBER 1
+//	REM COMMENT "Text"
+//	REM REPLAYGAIN_ALBUM_GAIN -6.2 dB
 func ParseRemComment(rem string) (*RemField, bool) {
 	if len(rem) == 0 {
 		return nil, false
@@ -573,18 +1192,20 @@ func ParseRemComment(rem string) (*RemField, bool) {
 	}
 
 	key := strings.ToUpper(parts[0])
-	value := ""
+	value, raw := "", ""
 	if len(parts) == 2 {
-		value = strings.TrimSpace(parts[1])
+		raw = strings.TrimSpace(parts[1])
+		value = raw
 		// Remove quotes if present
 		if len(value) > 0 && (value[0] == '"' || value[0] == '\'') {
-			value = unquote(value)
+			value, _ = unquote(value)
 		}
 	}
 
 	field := &RemField{
 		Key:   key,
 		Value: value,
+		Raw:   raw,
 	}
 
 	// Determine RemType
@@ -605,13 +1226,33 @@ func ParseRemComment(rem string) (*RemField, bool) {
 		field.Type = RemReplayGainTrackGain
 	case "REPLAYGAIN_TRACK_PEAK":
 		field.Type = RemReplayGainTrackPeak
+	case "REPLAYGAIN_REFERENCE_LOUDNESS":
+		field.Type = RemReplayGainReferenceLoudness
+	case "REPLAYGAIN_ALBUM_RANGE":
+		field.Type = RemReplayGainAlbumRange
+	case "REPLAYGAIN_TRACK_RANGE":
+		field.Type = RemReplayGainTrackRange
 	default:
-		field.Type = RemUnknown
+		if strings.HasPrefix(key, "REPLAYGAIN_") {
+			field.Type = RemReplayGainOther
+		} else {
+			field.Type = RemUnknown
+		}
 	}
 
 	return field, true
 }
 
+// GetRemValue returns the value of the first track REM field with the given type
+func (t *Track) GetRemValue(typ RemType) (string, bool) {
+	for _, rem := range t.Rem {
+		if field, ok := ParseRemComment(rem); ok && field.Type == typ {
+			return field.Value, true
+		}
+	}
+	return "", false
+}
+
 // GetRemFields returns all parsed REM fields from the cuesheet
 func (c *Cuesheet) GetRemFields() []RemField {
 	var fields []RemField
@@ -644,6 +1285,106 @@ func (c *Cuesheet) GetRemByKey(key string) (string, bool) {
 	return "", false
 }
 
+// HasRemKey returns true if a REM field with the given key is present,
+// including flag-like fields without a value such as REM COMPILATION
+func (c *Cuesheet) HasRemKey(key string) bool {
+	_, ok := c.GetRemByKey(key)
+	return ok
+}
+
+// GetRawRemByKey returns the value of the first REM field with the given key
+// exactly as written, without any quote processing
+func (c *Cuesheet) GetRawRemByKey(key string) (string, bool) {
+	upperKey := strings.ToUpper(key)
+	for _, rem := range c.Rem {
+		if field, ok := ParseRemComment(rem); ok && field.Key == upperKey {
+			return field.Raw, true
+		}
+	}
+	return "", false
+}
+
+// DuplicateField is an album value stored both as a CD-TEXT command and as a REM field
+type DuplicateField struct {
+	Key         string // REM key, e.g. "GENRE"
+	CdTextValue string
+	RemValue    string
+}
+
+// Conflict returns true if the two copies disagree
+func (d DuplicateField) Conflict() bool {
+	return d.CdTextValue != d.RemValue
+}
+
+// cdTextField returns the album CD-TEXT field that a REM key duplicates
+func (c *Cuesheet) cdTextField(key string) *string {
+	switch key {
+	case "GENRE":
+		return &c.Genre
+	case "COMPOSER":
+		return &c.Composer
+	case "ARRANGER":
+		return &c.Arranger
+	case "MESSAGE":
+		return &c.Message
+	case "DISCID", "DISC_ID":
+		return &c.DiscId
+	case "UPC_EAN":
+		return &c.UpcEan
+	}
+	return nil
+}
+
+// DuplicateFields reports album values present both in CD-TEXT and in REM,
+// such as GENRE "Rock" alongside REM GENRE "Rock"
+func (c *Cuesheet) DuplicateFields() []DuplicateField {
+	var dups []DuplicateField
+	for _, rem := range c.Rem {
+		field, ok := ParseRemComment(rem)
+		if !ok {
+			continue
+		}
+		if cdText := c.cdTextField(field.Key); cdText != nil && *cdText != "" {
+			dups = append(dups, DuplicateField{Key: field.Key, CdTextValue: *cdText, RemValue: field.Value})
+		}
+	}
+	return dups
+}
+
+// RemoveDuplicateFields removes the redundant copy of every value that is
+// stored identically in CD-TEXT and REM. The REM copy is dropped unless
+// keepRem is set, in which case the CD-TEXT field is cleared instead
+// Copies that disagree are left in place and returned as conflicts
+func (c *Cuesheet) RemoveDuplicateFields(keepRem bool) []DuplicateField {
+	var conflicts []DuplicateField
+	rems := c.Rem[:0]
+	for _, rem := range c.Rem {
+		field, ok := ParseRemComment(rem)
+		var cdText *string
+		if ok {
+			cdText = c.cdTextField(field.Key)
+		}
+		if cdText == nil || *cdText == "" {
+			rems = append(rems, rem)
+			continue
+		}
+		if *cdText != field.Value {
+			conflicts = append(conflicts, DuplicateField{Key: field.Key, CdTextValue: *cdText, RemValue: field.Value})
+			rems = append(rems, rem)
+			continue
+		}
+		if keepRem {
+			*cdText = ""
+			rems = append(rems, rem)
+		}
+	}
+	if len(rems) == 0 {
+		rems = nil
+	}
+	c.Rem = rems
+	return conflicts
+}
+
 // Helper methods
 
 // GetTrack returns the track with the specified number
@@ -655,10 +1396,33 @@ func (c *Cuesheet) GetTrack(number uint) (*Track, error) {
 			}
 		}
 	}
-	return nil, errors.New("track not found")
+	return nil, ErrTrackNotFound
 }
 
-// TrackCount returns the total number of tracks across all files
+// CrossfadePoints returns the frames of the sub-indexes (INDEX 02 and up) of
+// a track, ordered by index number. Some sheets use them to mark intro and
+// outro points for crossfading players
+func (c *Cuesheet) CrossfadePoints(trackNumber uint) ([]Frame, error) {
+	track, err := c.GetTrack(trackNumber)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]TrackIndex, 0, len(track.Index))
+	for _, index := range track.Index {
+		if index.Number >= 2 {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool { return indexes[i].Number < indexes[j].Number })
+
+	frames := make([]Frame, len(indexes))
+	for i, index := range indexes {
+		frames[i] = index.Frame
+	}
+	return frames, nil
+}
+
+// TrackCount returns the total number of tracks across all files
 func (c *Cuesheet) TrackCount() int {
 	count := 0
 	for i := range c.File {
@@ -667,26 +1431,275 @@ func (c *Cuesheet) TrackCount() int {
 	return count
 }
 
-// TotalDuration calculates the total duration of all tracks
-// Returns the duration from the start of the first track to the end of the last track
-func (c *Cuesheet) TotalDuration() time.Duration {
-	if len(c.File) == 0 {
-		return 0
+// AllTracks returns pointers to every track in file and track order
+// The pointers stay valid until tracks are added to or removed from a file
+func (c *Cuesheet) AllTracks() []*Track {
+	tracks := make([]*Track, 0, c.TrackCount())
+	for i := range c.File {
+		for j := range c.File[i].Tracks {
+			tracks = append(tracks, &c.File[i].Tracks[j])
+		}
 	}
-	var lastFrame Frame
+	return tracks
+}
+
+// NextTrack returns the track following t, crossing FILE boundaries, or nil
+// if t is the last track or not part of the cuesheet
+func (c *Cuesheet) NextTrack(t *Track) *Track {
+	found := false
 	for i := range c.File {
 		for j := range c.File[i].Tracks {
-			track := &c.File[i].Tracks[j]
-			if len(track.Index) > 0 {
-				for k := range track.Index {
-					if track.Index[k].Frame > lastFrame {
-						lastFrame = track.Index[k].Frame
-					}
+			if found {
+				return &c.File[i].Tracks[j]
+			}
+			found = &c.File[i].Tracks[j] == t
+		}
+	}
+	return nil
+}
+
+// TrackAt returns the track at zero-based position n in file and track
+// order, or nil if n is out of range
+func (c *Cuesheet) TrackAt(n int) *Track {
+	if n < 0 {
+		return nil
+	}
+	for i := range c.File {
+		if n < len(c.File[i].Tracks) {
+			return &c.File[i].Tracks[n]
+		}
+		n -= len(c.File[i].Tracks)
+	}
+	return nil
+}
+
+// Performers returns the distinct track performers in first-seen order
+// Tracks without a performer fall back to the album performer
+// Duplicates are detected case-insensitively, keeping the first-seen casing
+func (c *Cuesheet) Performers() []string {
+	var performers []string
+	seen := make(map[string]bool)
+	for i := range c.File {
+		for j := range c.File[i].Tracks {
+			performer := c.File[i].Tracks[j].Performer
+			if performer == "" {
+				performer = c.Performer
+			}
+			if performer == "" {
+				continue
+			}
+			key := strings.ToLower(performer)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			performers = append(performers, performer)
+		}
+	}
+	return performers
+}
+
+// TrackSongWriter returns the track songwriter, falling back to the album songwriter
+func (c *Cuesheet) TrackSongWriter(t *Track) string {
+	if t.SongWriter != "" {
+		return t.SongWriter
+	}
+	return c.SongWriter
+}
+
+var (
+	// performerSeparator matches "feat.", "ft.", "featuring", "vs.", "&", ";" and "/"
+	performerSeparator = regexp.MustCompile(`(?i)\s+(?:feat\.?|ft\.?|featuring|vs\.?|&)\s+|\s*[;/]\s*`)
+	// nameSuffix matches parts that continue a name after a comma, e.g. "Jr."
+	nameSuffix = regexp.MustCompile(`(?i)^(?:jr|sr|inc|ltd|ii|iii|iv)\.?$`)
+)
+
+// SplitPerformers splits a multi-artist PERFORMER value into individual names
+// Names are separated by "feat.", "ft.", "featuring", "vs.", "&", ";", "/" and commas
+// A comma followed by a suffix such as "Jr." or "Inc." is kept as part of the name
+func SplitPerformers(s string) []string {
+	var names []string
+	for _, part := range performerSeparator.Split(s, -1) {
+		for _, name := range strings.Split(part, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if nameSuffix.MatchString(name) && len(names) > 0 {
+				names[len(names)-1] += ", " + name
+				continue
+			}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// TotalDuration returns the playing time of the sheet without knowing the
+// audio lengths. The end of each file's last track is unknown, so the result
+// only reaches the start (largest INDEX) of the last track of each file and
+// is short by its playtime; use TotalDurationWithFileLengths when the audio
+// lengths are known
+func (c *Cuesheet) TotalDuration() time.Duration {
+	return c.TotalDurationWithFileLengths(nil)
+}
+
+// TotalDurationWithFileLengths returns the playing time of the sheet, summing
+// the length of every file keyed by FileName in lengths
+// A file missing from lengths contributes only up to its largest INDEX, read
+// as a disc position when IndexAddressing reports absolute times, so earlier
+// files are not counted twice
+func (c *Cuesheet) TotalDurationWithFileLengths(lengths map[string]Frame) time.Duration {
+	return c.totalFrames(lengths).ToDuration()
+}
+
+// totalFrames is TotalDurationWithFileLengths in frames
+func (c *Cuesheet) totalFrames(lengths map[string]Frame) Frame {
+	absolute := c.IndexAddressing() == AddressingAbsolute
+	var total Frame
+	for i := range c.File {
+		f := &c.File[i]
+		if length, ok := lengths[f.FileName]; ok {
+			total += length
+			continue
+		}
+		var lastFrame Frame
+		for j := range f.Tracks {
+			for _, index := range f.Tracks[j].Index {
+				if index.Frame > lastFrame {
+					lastFrame = index.Frame
 				}
 			}
 		}
+		switch {
+		case !absolute:
+			total += lastFrame
+		case lastFrame > total:
+			total = lastFrame
+		}
+	}
+	return total
+}
+
+// IsDataDisc returns true if the cuesheet has tracks and all of them are data tracks
+func (c *Cuesheet) IsDataDisc() bool {
+	if c.TrackCount() == 0 {
+		return false
+	}
+	for i := range c.File {
+		for j := range c.File[i].Tracks {
+			if !c.File[i].Tracks[j].IsDataTrack() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Standard recordable CD media lengths
+const (
+	CD74MinuteMedia = 74 * time.Minute
+	CD80MinuteMedia = 80 * time.Minute
+)
+
+// FitsOnCD reports whether the disc fits on media of the given length
+// The size compared to mediaLength is TotalSectors(leadout), the MSF lead-out
+// time including the 2-second lead-in, as burning tools report it; leadout is
+// the program-area length in frames, or 0 to estimate it from the sheet
+// If it does not fit, the returned duration is the overage
+func (c *Cuesheet) FitsOnCD(mediaLength time.Duration, leadout Frame) (bool, time.Duration) {
+	size := Frame(c.TotalSectors(leadout)).ToDuration()
+	if size > mediaLength {
+		return false, size - mediaLength
+	}
+	return true, 0
+}
+
+// programLength estimates the program-area length in frames from the sheet
+func (c *Cuesheet) programLength() Frame {
+	total := c.totalFrames(nil)
+	for _, t := range c.AllTracks() {
+		total += t.Pregap + t.Postgap
+	}
+	return total
+}
+
+// BaseName returns the file name without any directory prefix
+// Both Unix (/) and Windows (\) separators are recognized
+func (f *File) BaseName() string {
+	name := path.Base(strings.ReplaceAll(f.FileName, "\\", "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	return name
+}
+
+// CatalogEAN returns the catalog number if it is a valid EAN-13 code
+// The second result is false if the catalog is unset or fails validation
+func (c *Cuesheet) CatalogEAN() (string, bool) {
+	if ValidateCatalog(c.Catalog) != nil || !isValidEAN13(c.Catalog) {
+		return "", false
+	}
+	return c.Catalog, true
+}
+
+// Canonicalize normalizes values that tools write inconsistently
+// Hex disc IDs in DISC_ID and REM DISCID are uppercased, and ReplayGain
+// values are rewritten with fixed precision: two decimals for gains in dB
+// ("-6.2 dB" becomes "-6.20 dB") and six for peaks. FILE types are trimmed
+// and uppercased, so "wave" and a quoted " WAVE " both become WAVE
+// Album and track REM comments are both rewritten
+// Parsing never does this, so raw values are kept unless Canonicalize is called
+func (c *Cuesheet) Canonicalize() {
+	c.DiscId = strings.ToUpper(c.DiscId)
+	for i := range c.File {
+		c.File[i].FileType = strings.ToUpper(strings.TrimSpace(c.File[i].FileType))
+	}
+	canonicalizeRems(c.Rem)
+	for i := range c.File {
+		for j := range c.File[i].Tracks {
+			canonicalizeRems(c.File[i].Tracks[j].Rem)
+		}
+	}
+}
+
+// canonicalizeRems rewrites DISCID and ReplayGain REM values in place
+func canonicalizeRems(rems []string) {
+	for i, rem := range rems {
+		field, ok := ParseRemComment(rem)
+		if !ok || field.Raw == "" {
+			continue
+		}
+		value := field.Raw
+		switch {
+		case field.Key == "DISCID":
+			value = strings.ToUpper(value)
+		case field.Type.IsReplayGain():
+			value = canonicalReplayGain(field)
+		}
+		key := strings.SplitN(rem, " ", 2)[0]
+		rems[i] = key + " " + value
+	}
+}
+
+// canonicalReplayGain formats a numeric ReplayGain value with fixed precision
+// Values that are not numeric are returned as written
+func canonicalReplayGain(field *RemField) string {
+	number, unit, _ := strings.Cut(field.Value, " ")
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return field.Raw
+	}
+	switch field.Type {
+	case RemReplayGainAlbumPeak, RemReplayGainTrackPeak:
+		return strconv.FormatFloat(v, 'f', 6, 64)
+	case RemReplayGainOther:
+		return field.Raw
+	}
+	if unit == "" {
+		unit = "dB"
 	}
-	return lastFrame.ToDuration()
+	return strconv.FormatFloat(v, 'f', 2, 64) + " " + strings.TrimSpace(unit)
 }
 
 // GetIndex returns the index with the specified number
@@ -768,6 +1781,56 @@ func (t *Track) Duration(nextTrackStart Frame) time.Duration {
 	return (nextTrackStart - start).ToDuration()
 }
 
+// PlayingDuration calculates how long the track plays before the next track
+// Unlike Duration(next INDEX 01), the track ends at the next track's INDEX 00
+// when it has one, so the next track's pregap is not counted as part of this one
+// next must be the following track in the same file; for the last track use Duration
+func (t *Track) PlayingDuration(next *Track) time.Duration {
+	if idx00, ok := next.GetPregapIndex(); ok {
+		return t.Duration(idx00.Frame)
+	}
+	end, err := next.StartPosition()
+	if err != nil {
+		return 0
+	}
+	return t.Duration(end)
+}
+
+// TrackDurations returns the duration of every track in the file
+// Each track runs to the next track's INDEX 01; the last one runs to
+// fileLengthFrames, the length of the audio as reported by a decoder
+func (f *File) TrackDurations(fileLengthFrames Frame) []time.Duration {
+	durations := make([]time.Duration, len(f.Tracks))
+	for i := range f.Tracks {
+		end := fileLengthFrames
+		if i+1 < len(f.Tracks) {
+			next, err := f.Tracks[i+1].StartPosition()
+			if err != nil {
+				continue
+			}
+			end = next
+		}
+		durations[i] = f.Tracks[i].Duration(end)
+	}
+	return durations
+}
+
+// TrackDurations returns the duration of every track in sheet order
+// fileLengths holds audio lengths keyed by FileName, as for Flatten; the last
+// track of a file not in it gets a zero duration
+func (c *Cuesheet) TrackDurations(fileLengths map[string]time.Duration) []time.Duration {
+	var durations []time.Duration
+	for i := range c.File {
+		f := &c.File[i]
+		var length Frame
+		if d, ok := fileLengths[f.FileName]; ok {
+			length = DurationToFrame(d)
+		}
+		durations = append(durations, f.TrackDurations(length)...)
+	}
+	return durations
+}
+
 // HasFlag tests if a specific flag is set
 func (t *Track) HasFlag(flag Flags) bool {
 	return (t.Flags & flag) != 0
@@ -811,63 +1874,539 @@ func (t *Track) GetBlockSize() int {
 // ToDuration converts a Frame to time.Duration
 // 75 frames = 1 second (CD standard)
 func (f Frame) ToDuration() time.Duration {
-	seconds := float64(f) / framesPerSecond
+	return CDFrameScale.ToDuration(f)
+}
+
+// ToDuration converts a Frame counted at this scale to time.Duration
+func (fs FrameScale) ToDuration(f Frame) time.Duration {
+	seconds := float64(f) / float64(fs.orCD())
 	return time.Duration(seconds * float64(time.Second))
 }
 
 // ToSeconds converts a Frame to seconds as a float64
 func (f Frame) ToSeconds() float64 {
-	return float64(f) / framesPerSecond
+	return float64(f) / FramesPerSecond
+}
+
+// FormatTimecode formats a frame position as HH:MM:SS.mmm, the timecode used
+// by chapter formats such as FFmpeg metadata and Vorbis CHAPTERxxx tags
+func (f Frame) FormatTimecode() string {
+	return CDFrameScale.FormatTimecode(f)
+}
+
+// FormatTimecode formats a frame position at this scale as HH:MM:SS.mmm,
+// rounded to the nearest millisecond; hours are not limited to two digits
+func (fs FrameScale) FormatTimecode(f Frame) string {
+	rate := uint64(fs.orCD())
+	ms := (uint64(f)*1000 + rate/2) / rate
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
 }
 
 // DurationToFrame converts a time.Duration to Frame
 func DurationToFrame(d time.Duration) Frame {
+	return CDFrameScale.DurationToFrame(d)
+}
+
+// DurationToFrame converts a time.Duration to a Frame counted at this scale
+func (fs FrameScale) DurationToFrame(d time.Duration) Frame {
 	seconds := d.Seconds()
-	return Frame(seconds * framesPerSecond)
+	return Frame(seconds * float64(fs.orCD()))
+}
+
+// LeadInFrames is the 2-second offset between MSF disc time and LBA sector addresses
+// MSF 00:02:00 is LBA 0, so positions within the lead-in have negative LBAs
+const LeadInFrames = 150
+
+// ToLBA converts an absolute MSF frame position to a logical block address
+func (f Frame) ToLBA() int64 {
+	return int64(f) - LeadInFrames
+}
+
+// LBAToFrame converts a logical block address to an absolute MSF frame position
+// Addresses before the start of the lead-in (below -150) clamp to frame 0
+func LBAToFrame(lba int64) Frame {
+	if lba < -LeadInFrames {
+		return 0
+	}
+	return Frame(lba + LeadInFrames)
+}
+
+// TotalSectors returns the number of sectors on the disc up to the leadout
+// leadout is the program-area length in frames, the LBA of the lead-out track;
+// 0 estimates it from the sheet as TotalDuration plus every PREGAP and
+// POSTGAP, which is short by the playtime of each file's last track
+// The count includes the 150-frame (2 second) lead-in before LBA 0, matching
+// the MSF lead-out time reported by burning and verification tools
+func (c *Cuesheet) TotalSectors(leadout Frame) int64 {
+	if leadout == 0 {
+		leadout = c.programLength()
+	}
+	return int64(leadout) + LeadInFrames
 }
 
 // Validation functions
 
+// IsComplete reports whether the cuesheet has the structure of a finished
+// sheet: at least one FILE, a TRACK in every FILE and an INDEX 01 in every
+// TRACK. It is a quick check for sheets truncated mid-write; when incomplete,
+// the message describes the first problem found
+func (c *Cuesheet) IsComplete() (bool, string) {
+	if len(c.File) == 0 {
+		return false, "no FILE entries"
+	}
+	for i := range c.File {
+		f := &c.File[i]
+		if len(f.Tracks) == 0 {
+			return false, fmt.Sprintf("FILE %q has no tracks", f.FileName)
+		}
+		for j := range f.Tracks {
+			if _, err := f.Tracks[j].GetStartIndex(); err != nil {
+				return false, fmt.Sprintf("track %s has no INDEX 01", FormatTrackNumber(f.Tracks[j].TrackNumber))
+			}
+		}
+	}
+	return true, ""
+}
+
 // Validate checks the cuesheet for structural and data validity
+// It returns the errors of ValidateDetailed; warnings are not reported
+// Referenced files are not checked, since the sheet does not know its
+// directory; use ValidateDetailedInDir or CheckFiles for that
 func (c *Cuesheet) Validate() []error {
+	return c.ValidateDetailed().errs()
+}
+
+// IssueCode identifies the kind of problem a ValidationIssue reports
+type IssueCode string
+
+const (
+	IssueInvalidCatalog       IssueCode = "invalid-catalog"
+	IssueCatalogChecksum      IssueCode = "catalog-checksum"
+	IssueNoFiles              IssueCode = "no-files"
+	IssueInvalidFileType      IssueCode = "invalid-file-type"
+	IssueTrackNumberRange     IssueCode = "track-number-range"
+	IssueDuplicateTrack       IssueCode = "duplicate-track"
+	IssueTrackNumberGap       IssueCode = "track-number-gap"
+	IssueTrackOutOfOrder      IssueCode = "track-out-of-order"
+	IssueIndexNumberRange     IssueCode = "index-number-range"
+	IssueIndexOrder           IssueCode = "index-order"
+	IssueMissingIndex01       IssueCode = "missing-index-01"
+	IssuePregapConflict       IssueCode = "pregap-conflict"
+	IssueIndex00WithoutPregap IssueCode = "index-00-without-pregap"
+	IssueInvalidISRC          IssueCode = "invalid-isrc"
+	IssueInvalidTrackDataType IssueCode = "invalid-track-data-type"
+	IssueCdTextLength         IssueCode = "cd-text-length"
+	IssueCdTextCharset        IssueCode = "cd-text-charset"
+	IssueMissingFile          IssueCode = "missing-file"
+)
+
+// ValidationIssue is a single problem found by ValidateDetailed
+type ValidationIssue struct {
+	Code        IssueCode
+	Message     string // Human-readable description
+	FileIndex   int    // Index into Cuesheet.File, -1 for sheet-level issues
+	TrackNumber uint   // 0 when the issue is not about a track
+	Err         error  // Underlying error, as returned by Validate
+}
+
+func (i ValidationIssue) String() string {
+	return i.Message
+}
+
+// ValidationResult separates blocking errors from advisory warnings
+type ValidationResult struct {
+	Errors   []ValidationIssue // Problems that make the sheet invalid
+	Warnings []ValidationIssue // Unusual but playable constructs
+}
+
+// Valid reports whether no errors were found; warnings are allowed
+func (r ValidationResult) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// errs returns the underlying errors of r.Errors
+func (r ValidationResult) errs() []error {
 	var errs []error
+	for _, issue := range r.Errors {
+		errs = append(errs, issue.Err)
+	}
+	return errs
+}
+
+// ValidateDetailed checks the cuesheet like Validate, returning each problem
+// with a code and location and reporting advisories as warnings: a catalog
+// with a bad check digit, track numbers out of sequence and INDEX 00 without
+// a PREGAP command. Track numbering problems are warnings too, since some DJ
+// sheets restart numbering in every FILE, as are the CD-TEXT problems
+// reported by ValidateCdText with the MaxCdTextFieldLength limit
+func (c *Cuesheet) ValidateDetailed() ValidationResult {
+	var result ValidationResult
 
-	// Validate catalog (13 digits)
 	if len(c.Catalog) > 0 {
 		if err := ValidateCatalog(c.Catalog); err != nil {
-			errs = append(errs, err)
+			result.Errors = append(result.Errors, ValidationIssue{IssueInvalidCatalog,
+				fmt.Sprintf("CATALOG %q is not 13 digits", c.Catalog), -1, 0, err})
+		} else if err := ValidateCatalogChecksum(c.Catalog); err != nil {
+			result.Warnings = append(result.Warnings, ValidationIssue{IssueCatalogChecksum,
+				fmt.Sprintf("CATALOG %q has an invalid check digit", c.Catalog), -1, 0, err})
 		}
 	}
 
-	// Validate files
 	if len(c.File) == 0 {
-		errs = append(errs, strconv.ErrSyntax)
+		result.Errors = append(result.Errors, ValidationIssue{IssueNoFiles,
+			"no FILE entries", -1, 0, strconv.ErrSyntax})
 	}
 
-	for _, file := range c.File {
-		// Validate file type
+	for i, file := range c.File {
 		if err := ValidateFileType(file.FileType); err != nil {
-			errs = append(errs, err)
+			result.Errors = append(result.Errors, ValidationIssue{IssueInvalidFileType,
+				fmt.Sprintf("FILE %q has unknown type %q", file.FileName, file.FileType), i, 0, err})
 		}
 
-		// Validate tracks
-		for _, track := range file.Tracks {
-			if trackErrs := track.Validate(); len(trackErrs) > 0 {
-				errs = append(errs, trackErrs...)
+		for j := range file.Tracks {
+			file.Tracks[j].validate(i, &result)
+		}
+
+		file.checkIndexOrder(func(err *IndexOrderError) {
+			result.Errors = append(result.Errors, ValidationIssue{IssueIndexOrder, err.Error(), i, err.TrackNumber, err})
+		})
+	}
+
+	c.checkTrackSequence(func(fileIndex int, err *TrackError) {
+		code := IssueTrackNumberGap
+		switch err.Err {
+		case ErrDuplicateTrack:
+			code = IssueDuplicateTrack
+		case ErrTrackOutOfOrder:
+			code = IssueTrackOutOfOrder
+		}
+		result.Warnings = append(result.Warnings, ValidationIssue{code, err.Error(), fileIndex, err.TrackNumber, err})
+	})
+
+	result.Warnings = append(result.Warnings, c.ValidateCdText(MaxCdTextFieldLength)...)
+
+	return result
+}
+
+// ValidateDetailedInDir is ValidateDetailed plus the CheckFiles check against
+// dir, reporting every missing FILE or CDTEXTFILE as an IssueMissingFile error
+func (c *Cuesheet) ValidateDetailedInDir(dir string) ValidationResult {
+	result := c.ValidateDetailed()
+	c.checkFiles(dir, func(fileIndex int, name string, err error) {
+		result.Errors = append(result.Errors, ValidationIssue{IssueMissingFile,
+			fmt.Sprintf("%q cannot be read", name), fileIndex, 0, err})
+	})
+	return result
+}
+
+// ErrPregapConflict reports a track with both a PREGAP command and an INDEX 00
+var ErrPregapConflict = errors.New("PREGAP and INDEX 00 both define the pregap")
+
+// TrackError associates a validation error with the track it was found in
+type TrackError struct {
+	TrackNumber uint
+	Err         error
+}
+
+func (e *TrackError) Error() string {
+	return "track " + FormatTrackNumber(e.TrackNumber) + ": " + e.Err.Error()
+}
+
+func (e *TrackError) Unwrap() error {
+	return e.Err
+}
+
+// CheckFiles verifies that every FILE and the CDTEXTFILE exist
+// Relative names are resolved against dir, normally the CUE file's directory
+// Each missing or unreadable file is reported with its os.Stat error
+func (c *Cuesheet) CheckFiles(dir string) []error {
+	var errs []error
+	c.checkFiles(dir, func(_ int, _ string, err error) {
+		errs = append(errs, err)
+	})
+	return errs
+}
+
+// checkFiles calls report for the CDTEXTFILE (fileIndex -1) and every FILE
+// that cannot be found in dir
+func (c *Cuesheet) checkFiles(dir string, report func(fileIndex int, name string, err error)) {
+	if c.CdTextFile != "" {
+		if _, err := os.Stat(resolvePath(dir, c.CdTextFile)); err != nil {
+			report(-1, c.CdTextFile, err)
+		}
+	}
+	for i := range c.File {
+		if _, err := os.Stat(resolvePath(dir, c.File[i].FileName)); err != nil {
+			report(i, c.File[i].FileName, err)
+		}
+	}
+}
+
+// ResolveFilePaths returns the path of every FILE resolved against cueDir,
+// normally the CUE file's directory, in sheet order
+// Both Windows (\) and Unix (/) separators are accepted; absolute names are
+// kept and every path is cleaned, so "sub\..\a.flac" becomes cueDir/a.flac
+func (c *Cuesheet) ResolveFilePaths(cueDir string) []string {
+	paths := make([]string, len(c.File))
+	for i := range c.File {
+		paths[i] = resolvePath(cueDir, c.File[i].FileName)
+	}
+	return paths
+}
+
+// resolvePath resolves a CUE file reference against dir
+// Windows separators are converted so references written on Windows still resolve
+func resolvePath(dir, name string) string {
+	name = filepath.FromSlash(strings.ReplaceAll(name, "\\", "/"))
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name)
+	}
+	return filepath.Join(dir, name)
+}
+
+// ErrInterleavedDataTrack reports a data track placed between audio tracks
+var ErrInterleavedDataTrack = errors.New("data track interleaved between audio tracks")
+
+// ValidateTrackModes warns about data tracks that sit between audio tracks
+// Data tracks are expected at the start (mixed mode) or at the end (enhanced CD)
+func (c *Cuesheet) ValidateTrackModes() []error {
+	tracks := c.AllTracks()
+
+	firstAudio, lastAudio := -1, -1
+	for i, track := range tracks {
+		if !track.IsDataTrack() {
+			if firstAudio < 0 {
+				firstAudio = i
 			}
+			lastAudio = i
 		}
 	}
 
+	var errs []error
+	for i := firstAudio + 1; i < lastAudio; i++ {
+		if tracks[i].IsDataTrack() {
+			errs = append(errs, &TrackError{tracks[i].TrackNumber, ErrInterleavedDataTrack})
+		}
+	}
 	return errs
 }
 
+// MinTrackLength is the Red Book minimum track length; shorter tracks in a
+// single-file rip usually point to a misplaced INDEX
+const MinTrackLength = 4 * time.Second
+
+// ShortTrackError reports a track that plays for less than the minimum length
+type ShortTrackError struct {
+	TrackNumber uint
+	Duration    time.Duration
+	MinLength   time.Duration
+}
+
+func (e *ShortTrackError) Error() string {
+	return "track " + FormatTrackNumber(e.TrackNumber) + ": duration " + e.Duration.String() +
+		" is shorter than " + e.MinLength.String()
+}
+
+// ValidateTrackLengths reports every track shorter than minLength, usually MinTrackLength
+// A track's length runs from its INDEX 01 to the next track's INDEX 01 in the
+// same file, so the last track of each file and tracks without INDEX 01 are
+// not checked
+func (c *Cuesheet) ValidateTrackLengths(minLength time.Duration) []error {
+	var errs []error
+	for i := range c.File {
+		tracks := c.File[i].Tracks
+		for j := 0; j+1 < len(tracks); j++ {
+			if _, err := tracks[j].StartPosition(); err != nil {
+				continue
+			}
+			next, err := tracks[j+1].StartPosition()
+			if err != nil {
+				continue
+			}
+			if d := tracks[j].Duration(next); d < minLength {
+				errs = append(errs, &ShortTrackError{tracks[j].TrackNumber, d, minLength})
+			}
+		}
+	}
+	return errs
+}
+
+// IndexBoundsError reports an INDEX positioned past the end of its audio file
+type IndexBoundsError struct {
+	FileName    string
+	TrackNumber uint
+	IndexNumber uint
+	Frame       Frame
+	Length      time.Duration // Length of the audio file
+}
+
+func (e *IndexBoundsError) Error() string {
+	return "track " + FormatTrackNumber(e.TrackNumber) + " INDEX " + FormatTrackNumber(e.IndexNumber) +
+		" at " + FormatFrame(e.Frame) + " is past the end of " + e.FileName + " (" + e.Length.String() + ")"
+}
+
+// ValidateIndexBounds reports every INDEX at or past the end of its file,
+// which usually means the sheet was made for a different rip of the audio
+// fileLengths holds audio lengths keyed by FileName; files not in it are skipped
+func (c *Cuesheet) ValidateIndexBounds(fileLengths map[string]time.Duration) []error {
+	var errs []error
+	for i := range c.File {
+		f := &c.File[i]
+		length, ok := fileLengths[f.FileName]
+		if !ok {
+			continue
+		}
+		for _, track := range f.Tracks {
+			for _, index := range track.Index {
+				if index.Frame.ToDuration() >= length {
+					errs = append(errs, &IndexBoundsError{f.FileName, track.TrackNumber, index.Number, index.Frame, length})
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// FileTrackCountError reports a FILE of a file-per-track sheet that does not
+// hold exactly one track
+type FileTrackCountError struct {
+	FileName string
+	Tracks   int
+}
+
+func (e *FileTrackCountError) Error() string {
+	return "FILE " + e.FileName + " has " + strconv.Itoa(e.Tracks) + " tracks, expected 1"
+}
+
+// ValidateFilePerTrack reports every FILE without exactly one track in a sheet
+// meant to be split per track, which usually means a corrupted FILE block
+// A sheet counts as split when it has several files and more than half of
+// them hold exactly one track; other layouts are not checked
+func (c *Cuesheet) ValidateFilePerTrack() []error {
+	single := 0
+	for i := range c.File {
+		if len(c.File[i].Tracks) == 1 {
+			single++
+		}
+	}
+	if len(c.File) < 2 || 2*single <= len(c.File) {
+		return nil
+	}
+
+	var errs []error
+	for i := range c.File {
+		if n := len(c.File[i].Tracks); n != 1 {
+			errs = append(errs, &FileTrackCountError{c.File[i].FileName, n})
+		}
+	}
+	return errs
+}
+
+// IndexOrderError reports an INDEX positioned before the index preceding it,
+// either an earlier index of the same track or one of the previous track
+type IndexOrderError struct {
+	TrackNumber uint
+	IndexNumber uint
+	Frame       Frame
+	Previous    Frame // Position of the preceding index
+}
+
+func (e *IndexOrderError) Error() string {
+	return "track " + FormatTrackNumber(e.TrackNumber) + " INDEX " + FormatTrackNumber(e.IndexNumber) +
+		" at " + FormatFrame(e.Frame) + " is before the preceding index at " + FormatFrame(e.Previous)
+}
+
+// ValidateIndexOrder checks that INDEX positions never move backwards within
+// a file: the indexes of a track, taken in index-number order, and successive
+// tracks must have non-decreasing frames. Every break is an IndexOrderError
+func (c *Cuesheet) ValidateIndexOrder() []error {
+	var errs []error
+	for i := range c.File {
+		c.File[i].checkIndexOrder(func(err *IndexOrderError) {
+			errs = append(errs, err)
+		})
+	}
+	return errs
+}
+
+// checkIndexOrder calls report for every index of the file positioned
+// before the index preceding it
+func (f *File) checkIndexOrder(report func(err *IndexOrderError)) {
+	var previous Frame
+	for _, track := range f.Tracks {
+		indexes := cloneSlice(track.Index)
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return indexes[i].Number < indexes[j].Number
+		})
+		for _, index := range indexes {
+			if index.Frame < previous {
+				report(&IndexOrderError{track.TrackNumber, index.Number, index.Frame, previous})
+				continue
+			}
+			previous = index.Frame
+		}
+	}
+}
+
+var (
+	// ErrTrackNumberGap reports a track number that skips ahead of the sequence
+	ErrTrackNumberGap = errors.New("track numbers are not consecutive")
+	// ErrTrackOutOfOrder reports a track numbered lower than a track before it
+	ErrTrackOutOfOrder = errors.New("track number lower than a previous track")
+)
+
+// ValidateTrackSequence checks that track numbers across all files run 1, 2,
+// 3 and so on, reporting each duplicate, gap or out-of-order track as a
+// TrackError wrapping ErrDuplicateTrack, ErrTrackNumberGap or ErrTrackOutOfOrder
+func (c *Cuesheet) ValidateTrackSequence() []error {
+	var errs []error
+	c.checkTrackSequence(func(_ int, err *TrackError) {
+		errs = append(errs, err)
+	})
+	return errs
+}
+
+// checkTrackSequence calls report for every track that breaks the numbering
+// sequence, with the index of the file holding it
+func (c *Cuesheet) checkTrackSequence(report func(fileIndex int, err *TrackError)) {
+	seen := make(map[uint]bool)
+	var highest uint
+	for i := range c.File {
+		for _, track := range c.File[i].Tracks {
+			n := track.TrackNumber
+			switch {
+			case seen[n]:
+				report(i, &TrackError{n, ErrDuplicateTrack})
+			case n < highest:
+				report(i, &TrackError{n, ErrTrackOutOfOrder})
+			case n != highest+1:
+				report(i, &TrackError{n, ErrTrackNumberGap})
+			}
+			seen[n] = true
+			if n > highest {
+				highest = n
+			}
+		}
+	}
+}
+
 // Validate checks the track for structural and data validity
 func (t *Track) Validate() []error {
-	var errs []error
+	var result ValidationResult
+	t.validate(-1, &result)
+	return result.errs()
+}
+
+// validate adds the track's issues to result, located in file fileIndex
+func (t *Track) validate(fileIndex int, result *ValidationResult) {
+	track := "track " + FormatTrackNumber(t.TrackNumber)
+	fail := func(code IssueCode, message string, err error) {
+		result.Errors = append(result.Errors, ValidationIssue{code, message, fileIndex, t.TrackNumber, err})
+	}
 
 	// Track number range (1-99)
 	if t.TrackNumber < 1 || t.TrackNumber > 99 {
-		errs = append(errs, strconv.ErrRange)
+		fail(IssueTrackNumberRange, track+": number out of range 1-99", strconv.ErrRange)
 	}
 
 	// Must have at least INDEX 01
@@ -878,28 +2417,147 @@ func (t *Track) Validate() []error {
 		}
 		// Index range (0-99)
 		if idx.Number > 99 {
-			errs = append(errs, strconv.ErrRange)
+			fail(IssueIndexNumberRange, fmt.Sprintf("%s: INDEX %d out of range 0-99", track, idx.Number), strconv.ErrRange)
 		}
 	}
 	if !hasIndex01 {
-		errs = append(errs, strconv.ErrSyntax)
+		fail(IssueMissingIndex01, track+": no INDEX 01", strconv.ErrSyntax)
+	}
+
+	// The pregap is either a PREGAP command or INDEX 00, never both
+	if t.Pregap > 0 && t.HasPregap() {
+		fail(IssuePregapConflict, track+": "+ErrPregapConflict.Error(), &TrackError{t.TrackNumber, ErrPregapConflict})
+	} else if t.HasPregap() {
+		result.Warnings = append(result.Warnings, ValidationIssue{IssueIndex00WithoutPregap,
+			track + ": INDEX 00 without a PREGAP command", fileIndex, t.TrackNumber, nil})
 	}
 
 	// Validate ISRC format
 	if len(t.Isrc) > 0 {
 		if err := ValidateISRC(t.Isrc); err != nil {
-			errs = append(errs, err)
+			fail(IssueInvalidISRC, fmt.Sprintf("%s: invalid ISRC %q", track, t.Isrc), err)
 		}
 	}
 
 	// Validate track data type
 	if err := ValidateTrackDataType(t.TrackDataType); err != nil {
-		errs = append(errs, err)
+		fail(IssueInvalidTrackDataType, fmt.Sprintf("%s: unknown data type %q", track, t.TrackDataType), err)
 	}
+}
 
+// MaxCdTextFieldLength is the maximum length in characters of a single CD-TEXT field
+const MaxCdTextFieldLength = 160
+
+// CdTextLengthError reports a CD-TEXT field exceeding the length limit
+type CdTextLengthError struct {
+	TrackNumber uint   // 0 for album-level fields
+	Field       string // CUE command name, e.g. "TITLE"
+	Length      int    // Field length in characters
+	Limit       int
+}
+
+func (e *CdTextLengthError) Error() string {
+	where := "album"
+	if e.TrackNumber > 0 {
+		where = "track " + FormatTrackNumber(e.TrackNumber)
+	}
+	return where + " " + e.Field + ": length " + strconv.Itoa(e.Length) +
+		" exceeds limit of " + strconv.Itoa(e.Limit)
+}
+
+// ValidateCdTextLength reports every CD-TEXT field longer than limit characters
+func (c *Cuesheet) ValidateCdTextLength(limit int) []error {
+	var errs []error
+	c.forEachCdText(func(_ int, trackNumber uint, field, value string) {
+		if n := utf8.RuneCountInString(value); n > limit {
+			errs = append(errs, &CdTextLengthError{trackNumber, field, n, limit})
+		}
+	})
 	return errs
 }
 
+// CdTextCharsetError reports a CD-TEXT field with a character that neither
+// ISO-8859-1 nor MS-JIS can encode, such as an emoji
+type CdTextCharsetError struct {
+	TrackNumber uint   // 0 for album-level fields
+	Field       string // CUE command name, e.g. "TITLE"
+	Char        rune   // First character that cannot be encoded
+}
+
+func (e *CdTextCharsetError) Error() string {
+	where := "album"
+	if e.TrackNumber > 0 {
+		where = "track " + FormatTrackNumber(e.TrackNumber)
+	}
+	return where + " " + e.Field + ": character " + strconv.QuoteRune(e.Char) + " cannot be encoded in CD-TEXT"
+}
+
+// ValidateCdText warns about CD-TEXT fields burning software would reject:
+// fields longer than limit characters (MaxCdTextFieldLength if limit is not
+// positive) and fields with characters outside ISO-8859-1 and MS-JIS
+// MS-JIS support is approximated by the kana, CJK ideograph and full-width
+// ranges, so all reported issues are warnings
+func (c *Cuesheet) ValidateCdText(limit int) []ValidationIssue {
+	if limit <= 0 {
+		limit = MaxCdTextFieldLength
+	}
+	var issues []ValidationIssue
+	c.forEachCdText(func(fileIndex int, trackNumber uint, field, value string) {
+		if n := utf8.RuneCountInString(value); n > limit {
+			err := &CdTextLengthError{trackNumber, field, n, limit}
+			issues = append(issues, ValidationIssue{IssueCdTextLength, err.Error(), fileIndex, trackNumber, err})
+		}
+		for _, r := range value {
+			if !isCdTextRune(r) {
+				err := &CdTextCharsetError{trackNumber, field, r}
+				issues = append(issues, ValidationIssue{IssueCdTextCharset, err.Error(), fileIndex, trackNumber, err})
+				break
+			}
+		}
+	})
+	return issues
+}
+
+// isCdTextRune reports whether r can be written in a CD-TEXT block, either
+// ISO-8859-1 or (approximately) MS-JIS
+func isCdTextRune(r rune) bool {
+	switch {
+	case r <= 0xff:
+		return true
+	case r >= 0x3000 && r <= 0x30ff: // CJK punctuation, hiragana, katakana
+		return true
+	case r >= 0x4e00 && r <= 0x9fff: // CJK unified ideographs
+		return true
+	case r >= 0xff00 && r <= 0xffef: // Full-width and half-width forms
+		return true
+	}
+	return false
+}
+
+// forEachCdText calls fn for every CD-TEXT field, album fields first, with
+// the index of the file holding a track field or -1 for album fields
+func (c *Cuesheet) forEachCdText(fn func(fileIndex int, trackNumber uint, field, value string)) {
+	fn(-1, 0, "TITLE", c.Title)
+	fn(-1, 0, "PERFORMER", c.Performer)
+	fn(-1, 0, "SONGWRITER", c.SongWriter)
+	fn(-1, 0, "COMPOSER", c.Composer)
+	fn(-1, 0, "ARRANGER", c.Arranger)
+	fn(-1, 0, "MESSAGE", c.Message)
+	fn(-1, 0, "GENRE", c.Genre)
+
+	for i := range c.File {
+		for j := range c.File[i].Tracks {
+			track := &c.File[i].Tracks[j]
+			fn(i, track.TrackNumber, "TITLE", track.Title)
+			fn(i, track.TrackNumber, "PERFORMER", track.Performer)
+			fn(i, track.TrackNumber, "SONGWRITER", track.SongWriter)
+			fn(i, track.TrackNumber, "COMPOSER", track.Composer)
+			fn(i, track.TrackNumber, "ARRANGER", track.Arranger)
+			fn(i, track.TrackNumber, "MESSAGE", track.Message)
+		}
+	}
+}
+
 // ValidateCatalog checks if the catalog number is valid (13 digits)
 func ValidateCatalog(catalog string) error {
 	if len(catalog) != 13 {
@@ -913,18 +2571,46 @@ func ValidateCatalog(catalog string) error {
 	return nil
 }
 
+// ErrCheckDigit reports a barcode whose last digit does not match its checksum
+var ErrCheckDigit = errors.New("invalid UPC/EAN check digit")
+
+// ValidateCatalogChecksum checks that the catalog is a 13-digit EAN-13 whose
+// last digit is the modulo-10 check digit (weights 1,3,1,3,...)
+// Use ValidateCatalog to check only the format
+func ValidateCatalogChecksum(catalog string) error {
+	if err := ValidateCatalog(catalog); err != nil {
+		return err
+	}
+	if !isValidEAN13(catalog) {
+		return ErrCheckDigit
+	}
+	return nil
+}
+
+// ValidateUPCEAN checks a UPC_EAN CD-TEXT value: a 12-digit UPC-A or a
+// 13-digit EAN-13 barcode with a correct check digit
+func ValidateUPCEAN(s string) error {
+	if len(s) == 12 {
+		s = "0" + s // UPC-A is EAN-13 with a leading zero
+	}
+	return ValidateCatalogChecksum(s)
+}
+
 // ValidateISRC checks if the ISRC code is valid
+// Only the canonical form is accepted: letters must be uppercase and
+// separators are not allowed; see NormalizeISRC
 // Format: CCOOOOYYSSSSS (12 characters)
-//   CC = country code (2 letters)
-//   OOOOO = owner code (3 alphanumeric)
-//   YY = year (2 digits)
-//   SSSSS = serial (5 digits)
+//
+//	CC = country code (2 letters)
+//	OOOOO = owner code (3 alphanumeric)
+//	YY = year (2 digits)
+//	SSSSS = serial (5 digits)
 func ValidateISRC(isrc string) error {
 	if len(isrc) != 12 {
 		return strconv.ErrSyntax
 	}
 	// CC: 2 letters
-	if !isLetter(isrc[0]) || !isLetter(isrc[1]) {
+	if !isUpper(isrc[0]) || !isUpper(isrc[1]) {
 		return strconv.ErrSyntax
 	}
 	// OOOOO: 3 alphanumeric
@@ -943,13 +2629,35 @@ func ValidateISRC(isrc string) error {
 	return nil
 }
 
-// ValidFileTypes lists valid file types according to CUE specification
+// NormalizeISRC returns the canonical 12-character form of an ISRC written
+// with hyphens, spaces or lowercase letters, e.g. "us-rc1-76-07839" becomes
+// "USRC17607839". The result is checked with ValidateISRC
+func NormalizeISRC(s string) (string, error) {
+	isrc := strings.ToUpper(strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, s))
+	if err := ValidateISRC(isrc); err != nil {
+		return "", err
+	}
+	return isrc, nil
+}
+
+// ValidFileTypes lists valid file types: those of the CUE specification and
+// the container types written by rippers and players for compressed audio
 var ValidFileTypes = map[string]bool{
 	"BINARY":   true,
 	"MOTOROLA": true,
 	"AIFF":     true,
 	"WAVE":     true,
 	"MP3":      true,
+	"FLAC":     true,
+	"APE":      true,
+	"WAVPACK":  true,
+	"OGG":      true,
+	"MP4":      true,
 }
 
 // ValidateFileType checks if the file type is valid
@@ -968,14 +2676,15 @@ type TrackMode struct {
 
 // ValidTrackModes maps track data type names to their specifications
 var ValidTrackModes = map[string]TrackMode{
-	"AUDIO":        {"AUDIO", 2352},
-	"CDG":          {"CDG", 2448},
-	"MODE1/2048":   {"MODE1/2048", 2048},
-	"MODE1/2352":   {"MODE1/2352", 2352},
-	"MODE2/2336":   {"MODE2/2336", 2336},
-	"MODE2/2352":   {"MODE2/2352", 2352},
-	"CDI/2336":     {"CDI/2336", 2336},
-	"CDI/2352":     {"CDI/2352", 2352},
+	"AUDIO":      {"AUDIO", 2352},
+	"CDG":        {"CDG", 2448},
+	"MODE0/2352": {"MODE0/2352", 2352},
+	"MODE1/2048": {"MODE1/2048", 2048},
+	"MODE1/2352": {"MODE1/2352", 2352},
+	"MODE2/2336": {"MODE2/2336", 2336},
+	"MODE2/2352": {"MODE2/2352", 2352},
+	"CDI/2336":   {"CDI/2336", 2336},
+	"CDI/2352":   {"CDI/2352", 2352},
 }
 
 // ValidateTrackDataType checks if the track data type is valid
@@ -988,8 +2697,39 @@ func ValidateTrackDataType(dataType string) error {
 
 // Helper functions for validation
 
-func isLetter(c byte) bool {
-	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+// truncateRunes shortens s to at most n characters without splitting a UTF-8 sequence
+func truncateRunes(s string, n int) string {
+	count := 0
+	for i := range s {
+		if count == n {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
+
+// isValidEAN13 checks the check digit of a 13-digit EAN code
+func isValidEAN13(code string) bool {
+	if len(code) != 13 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 13; i++ {
+		if !isDigit(code[i]) {
+			return false
+		}
+		digit := int(code[i] - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
 }
 
 func isDigit(c byte) bool {
@@ -997,5 +2737,5 @@ func isDigit(c byte) bool {
 }
 
 func isAlphaNum(c byte) bool {
-	return isLetter(c) || isDigit(c)
+	return isUpper(c) || isDigit(c)
 }
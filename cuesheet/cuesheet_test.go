@@ -1,10 +1,15 @@
 package cuesheet
 
 import (
+	"errors"
+	"math/rand"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 const cueFile = "test.cue"
@@ -142,6 +147,28 @@ func TestEmptyFile(t *testing.T) {
 	}
 }
 
+func TestBlankFile(t *testing.T) {
+	empty, err := ReadFile(strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, input := range map[string]string{
+		"BOMOnly":        "\ufeff",
+		"BOMAndNewlines": "\ufeff\r\n\r\n",
+		"WhitespaceOnly": "  \n\t\n   \r\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			cuesheet, err := ReadFile(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(cuesheet, empty) {
+				t.Errorf("expected an empty cuesheet, got: %+v", cuesheet)
+			}
+		})
+	}
+}
+
 func TestInvalidFrameFormat(t *testing.T) {
 	input := `TITLE "Test Album"
 PERFORMER "Test Artist"
@@ -491,13 +518,15 @@ func TestValidation(t *testing.T) {
 	})
 
 	t.Run("ValidFileType", func(t *testing.T) {
-		if err := ValidateFileType("WAVE"); err != nil {
-			t.Errorf("expected valid file type, got error: %v", err)
+		for _, fileType := range []string{"WAVE", "FLAC"} {
+			if err := ValidateFileType(fileType); err != nil {
+				t.Errorf("expected valid file type %s, got error: %v", fileType, err)
+			}
 		}
 	})
 
 	t.Run("InvalidFileType", func(t *testing.T) {
-		if err := ValidateFileType("FLAC"); err == nil {
+		if err := ValidateFileType("WAV"); err == nil {
 			t.Error("expected error for invalid file type")
 		}
 	})
@@ -627,6 +656,17 @@ FILE "test.wav" WAVE
 		if size != 2352 {
 			t.Errorf("expected block size 2352 for AUDIO, got: %d", size)
 		}
+
+		mode0 := Track{TrackDataType: "MODE0/2352"}
+		if err := ValidateTrackDataType(mode0.TrackDataType); err != nil {
+			t.Errorf("expected MODE0/2352 to be valid, got: %v", err)
+		}
+		if size := mode0.GetBlockSize(); size != 2352 {
+			t.Errorf("expected block size 2352 for MODE0/2352, got: %d", size)
+		}
+		if !mode0.IsDataTrack() {
+			t.Error("expected MODE0/2352 to be a data track")
+		}
 	})
 
 	t.Run("FrameConversion", func(t *testing.T) {
@@ -802,6 +842,44 @@ func TestRemParsing(t *testing.T) {
 		}
 	})
 
+	t.Run("ParseRemReplayGainExtended", func(t *testing.T) {
+		tests := []struct {
+			rem      string
+			expected RemType
+		}{
+			{"REPLAYGAIN_REFERENCE_LOUDNESS 89.0 dB", RemReplayGainReferenceLoudness},
+			{"replaygain_album_range 7.35 dB", RemReplayGainAlbumRange},
+			{"REPLAYGAIN_TRACK_RANGE 5.10 dB", RemReplayGainTrackRange},
+			{"REPLAYGAIN_ALGORITHM \"EBU R128\"", RemReplayGainOther},
+			{"REPLAYGAINISH 1", RemUnknown},
+		}
+		for _, tt := range tests {
+			field, ok := ParseRemComment(tt.rem)
+			if !ok || field.Type != tt.expected {
+				t.Errorf("ParseRemComment(%q) type = %v, expected %v", tt.rem, field.Type, tt.expected)
+			}
+			if field.Type.IsReplayGain() != (tt.expected != RemUnknown) {
+				t.Errorf("unexpected IsReplayGain for %q", tt.rem)
+			}
+		}
+
+		input := "REM REPLAYGAIN_REFERENCE_LOUDNESS 89.0 dB\nREM REPLAYGAIN_ALGORITHM \"EBU R128\"\nFILE test.wav WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n"
+		cuesheet, err := ReadFile(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value, ok := cuesheet.GetRemValue(RemReplayGainReferenceLoudness); !ok || value != "89.0 dB" {
+			t.Errorf("expected reference loudness '89.0 dB', got: %q", value)
+		}
+		var sb strings.Builder
+		if err := WriteFile(&sb, cuesheet); err != nil {
+			t.Fatal(err)
+		}
+		if sb.String() != input {
+			t.Errorf("round-trip mismatch:\n%s", sb.String())
+		}
+	})
+
 	t.Run("GetRemValue", func(t *testing.T) {
 		cuesheet := Cuesheet{
 			Rem: []string{
@@ -1526,3 +1604,2258 @@ func TestSample2Cue(t *testing.T) {
 		}
 	})
 }
+
+func TestPerformers(t *testing.T) {
+	input := `TITLE "Compilation"
+PERFORMER "Various Artists"
+FILE "disc.wav" WAVE
+  TRACK 01 AUDIO
+    PERFORMER "Artist One"
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    PERFORMER "Artist Two"
+    INDEX 01 03:00:00
+  TRACK 03 AUDIO
+    PERFORMER "ARTIST ONE"
+    INDEX 01 06:00:00
+  TRACK 04 AUDIO
+    INDEX 01 09:00:00
+`
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+
+	expected := []string{"Artist One", "Artist Two", "Various Artists"}
+	if performers := cuesheet.Performers(); !reflect.DeepEqual(performers, expected) {
+		t.Errorf("expected %v, got: %v", expected, performers)
+	}
+
+	empty := Cuesheet{}
+	if performers := empty.Performers(); len(performers) != 0 {
+		t.Errorf("expected no performers, got: %v", performers)
+	}
+}
+
+func TestCdTextFieldLength(t *testing.T) {
+	atLimit := strings.Repeat("a", MaxCdTextFieldLength)
+	overLimit := strings.Repeat("b", MaxCdTextFieldLength+1)
+
+	cuesheet := Cuesheet{
+		Title:     atLimit,
+		Performer: overLimit,
+		File: []File{
+			{
+				FileName: "test.wav",
+				FileType: "WAVE",
+				Tracks: []Track{
+					{
+						TrackNumber:   1,
+						TrackDataType: "AUDIO",
+						Title:         strings.Repeat("я", MaxCdTextFieldLength+5),
+						Index:         []TrackIndex{{Number: 1, Frame: 0}},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("Validate", func(t *testing.T) {
+		errs := cuesheet.ValidateCdTextLength(MaxCdTextFieldLength)
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got: %v", errs)
+		}
+		albumErr, ok := errs[0].(*CdTextLengthError)
+		if !ok || albumErr.TrackNumber != 0 || albumErr.Field != "PERFORMER" || albumErr.Length != MaxCdTextFieldLength+1 {
+			t.Errorf("unexpected album error: %v", errs[0])
+		}
+		trackErr, ok := errs[1].(*CdTextLengthError)
+		if !ok || trackErr.TrackNumber != 1 || trackErr.Field != "TITLE" || trackErr.Length != MaxCdTextFieldLength+5 {
+			t.Errorf("unexpected track error: %v", errs[1])
+		}
+		if errs[1].Error() != "track 01 TITLE: length 165 exceeds limit of 160" {
+			t.Errorf("unexpected error message: %q", errs[1].Error())
+		}
+	})
+
+	t.Run("TruncateOnWrite", func(t *testing.T) {
+		var sb strings.Builder
+		if err := WriteFileWithOptions(&sb, &cuesheet, WriteOptions{MaxFieldLength: MaxCdTextFieldLength}); err != nil {
+			t.Fatal(err)
+		}
+		readBack, err := ReadFile(strings.NewReader(sb.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if readBack.Title != atLimit {
+			t.Error("expected title at the limit to be kept intact")
+		}
+		if readBack.Performer != overLimit[:MaxCdTextFieldLength] {
+			t.Errorf("expected performer truncated to %d characters, got %d", MaxCdTextFieldLength, len(readBack.Performer))
+		}
+		if readBack.File[0].Tracks[0].Title != strings.Repeat("я", MaxCdTextFieldLength) {
+			t.Error("expected multi-byte track title truncated by characters")
+		}
+		if errs := readBack.ValidateCdTextLength(MaxCdTextFieldLength); len(errs) != 0 {
+			t.Errorf("expected no length errors after truncation, got: %v", errs)
+		}
+	})
+}
+
+func TestFormatTrackNumberWide(t *testing.T) {
+	tests := []struct {
+		n        uint
+		expected string
+	}{
+		{1, "01"},
+		{99, "99"},
+		{100, "100"},
+		{1234, "1234"},
+	}
+	for _, tt := range tests {
+		if result := FormatTrackNumber(tt.n); result != tt.expected {
+			t.Errorf("FormatTrackNumber(%d) = %q, expected %q", tt.n, result, tt.expected)
+		}
+	}
+
+	original := Cuesheet{
+		File: []File{
+			{
+				FileName: "test.wav",
+				FileType: "WAVE",
+				Tracks: []Track{
+					{
+						TrackNumber:   100,
+						TrackDataType: "AUDIO",
+						Index: []TrackIndex{
+							{Number: 1, Frame: 0},
+							{Number: 100, Frame: 75},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var sb strings.Builder
+	if err := WriteFile(&sb, &original); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "TRACK 100 AUDIO") {
+		t.Errorf("expected 'TRACK 100 AUDIO' in output:\n%s", sb.String())
+	}
+	if !strings.Contains(sb.String(), "INDEX 100 00:01:00") {
+		t.Errorf("expected 'INDEX 100 00:01:00' in output:\n%s", sb.String())
+	}
+
+	readBack, err := ReadFile(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(original, *readBack) {
+		t.Errorf("round-trip mismatch: %+v", *readBack)
+	}
+}
+
+func TestTrackTrailingComment(t *testing.T) {
+	input := `FILE test.wav WAVE
+  TRACK 01 AUDIO ; first track
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    INDEX 01 03:00:00
+`
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	tracks := cuesheet.File[0].Tracks
+	if tracks[0].TrackDataType != "AUDIO" {
+		t.Errorf("expected data type 'AUDIO', got: '%s'", tracks[0].TrackDataType)
+	}
+	if tracks[0].Comment != "; first track" {
+		t.Errorf("expected comment '; first track', got: '%s'", tracks[0].Comment)
+	}
+	if tracks[1].Comment != "" {
+		t.Errorf("expected no comment on track 2, got: '%s'", tracks[1].Comment)
+	}
+
+	var sb strings.Builder
+	if err := WriteFile(&sb, cuesheet); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != input {
+		t.Errorf("round-trip mismatch:\n%s", sb.String())
+	}
+}
+
+func TestFitsOnCD(t *testing.T) {
+	input := `FILE "long.wav" WAVE
+  TRACK 01 AUDIO
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    INDEX 01 79:57:60
+`
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+
+	// 79:57:60 of audio plus the 2 second lead-in ends at 79:59:60
+	fits, over := cuesheet.FitsOnCD(CD80MinuteMedia, 0)
+	if !fits || over != 0 {
+		t.Errorf("expected content to fit on 80 minute media, got overage %v", over)
+	}
+
+	cuesheet.File[0].Tracks[1].Pregap = 30
+	fits, over = cuesheet.FitsOnCD(CD80MinuteMedia, 0)
+	if fits {
+		t.Error("expected content with a PREGAP not to fit on 80 minute media")
+	}
+	if expected := Frame(15).ToDuration(); over != expected {
+		t.Errorf("expected overage %v, got: %v", expected, over)
+	}
+
+	leadout := Frame(80*60*75 - LeadInFrames + 1)
+	fits, over = cuesheet.FitsOnCD(CD80MinuteMedia, leadout)
+	if fits || over != Frame(1).ToDuration() {
+		t.Errorf("expected a lead-out past 80:00:00 to be over by one frame, got: %v %v", fits, over)
+	}
+
+	fits, over = cuesheet.FitsOnCD(90*time.Minute, leadout)
+	if !fits || over != 0 {
+		t.Errorf("expected content to fit on 90 minute media, got overage %v", over)
+	}
+}
+
+func TestIsDataDisc(t *testing.T) {
+	dataInput := `FILE "image.bin" BINARY
+  TRACK 01 MODE1/2048
+    INDEX 01 00:00:00
+`
+	cuesheet, err := ReadFile(strings.NewReader(dataInput))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	if !cuesheet.IsDataDisc() {
+		t.Error("expected data-only cuesheet to be a data disc")
+	}
+
+	mixedInput := `FILE "image.bin" BINARY
+  TRACK 01 MODE1/2352
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    INDEX 01 10:00:00
+`
+	cuesheet, err = ReadFile(strings.NewReader(mixedInput))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	if cuesheet.IsDataDisc() {
+		t.Error("expected mixed-mode cuesheet not to be a data disc")
+	}
+
+	empty := Cuesheet{}
+	if empty.IsDataDisc() {
+		t.Error("expected empty cuesheet not to be a data disc")
+	}
+}
+
+func TestFrameRoundTrip(t *testing.T) {
+	frames := []Frame{
+		0, 1, 74, 75, 4499, 4500,
+		99*60*75 + 59*75 + 74, // 99:59:74
+		100 * 60 * 75,         // 100:00:00
+		999*60*75 + 1,
+		1 << 32,
+	}
+	for _, f := range frames {
+		s := FormatFrame(f)
+		parsed, err := ReadFrame(&s)
+		if err != nil {
+			t.Errorf("ReadFrame(FormatFrame(%d)) error: %v", f, err)
+			continue
+		}
+		if parsed != f {
+			t.Errorf("ReadFrame(FormatFrame(%d)) = %d (formatted %q)", f, parsed, FormatFrame(f))
+		}
+	}
+
+	if result := FormatFrame(100 * 60 * 75); result != "100:00:00" {
+		t.Errorf("expected '100:00:00', got: %q", result)
+	}
+}
+
+func FuzzFrameRoundTrip(f *testing.F) {
+	f.Add(uint64(0))
+	f.Add(uint64(4500))
+	f.Add(uint64(100 * 60 * 75))
+	f.Fuzz(func(t *testing.T, n uint64) {
+		// Minutes are parsed as 32-bit values
+		frame := Frame(n % (1 << 32 * FramesPerSecond))
+		s := FormatFrame(frame)
+		parsed, err := ReadFrame(&s)
+		if err != nil {
+			t.Fatalf("ReadFrame(%q) error: %v", FormatFrame(frame), err)
+		}
+		if parsed != frame {
+			t.Fatalf("ReadFrame(FormatFrame(%d)) = %d", frame, parsed)
+		}
+	})
+}
+
+func FuzzReadFile(f *testing.F) {
+	for _, name := range []string{"testdata/sample_1.cue", "testdata/sample_2.cue"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte("FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00"))
+	f.Add([]byte(""))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		cuesheet, err := ReadFile(strings.NewReader(string(data)))
+		if err != nil {
+			return
+		}
+
+		var sb strings.Builder
+		if err := WriteFile(&sb, cuesheet); err != nil {
+			t.Fatalf("WriteFile error: %v", err)
+		}
+		readBack, err := ReadFile(strings.NewReader(sb.String()))
+		if err != nil {
+			t.Fatalf("re-parse error: %v\n%s", err, sb.String())
+		}
+		if !reflect.DeepEqual(cuesheet, readBack) {
+			t.Fatalf("round-trip mismatch\nparsed:    %+v\nre-parsed: %+v\nwritten:\n%s", cuesheet, readBack, sb.String())
+		}
+	})
+}
+
+// randomText returns a CD-TEXT-like value that stresses quoting: spaces,
+// quote characters, backslashes and non-ASCII letters. Empty values are
+// common so that optional fields are exercised both ways.
+func randomText(r *rand.Rand) string {
+	if r.Intn(3) == 0 {
+		return ""
+	}
+	const alphabet = "abcXYZ019 '\"\\-_.,&()ёЖ中"
+	runes := []rune(alphabet)
+	n := 1 + r.Intn(24)
+	buf := make([]rune, n)
+	for i := range buf {
+		buf[i] = runes[r.Intn(len(runes))]
+	}
+	return string(buf)
+}
+
+func randomDigits(r *rand.Rand, n int) string {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte('0' + r.Intn(10))
+	}
+	return string(buf)
+}
+
+// randomCuesheet generates a cuesheet that WriteFile can represent exactly
+func randomCuesheet(r *rand.Rand) Cuesheet {
+	fileTypes := []string{"WAVE", "MP3", "AIFF", "BINARY", "MOTOROLA"}
+	dataTypes := []string{"AUDIO", "CDG", "MODE1/2048", "MODE1/2352", "MODE2/2336", "MODE2/2352", "CDI/2336", "CDI/2352"}
+	remKeys := []string{"GENRE", "DATE", "DISCID", "COMMENT", "REPLAYGAIN_ALBUM_GAIN", "CUSTOM"}
+
+	c := Cuesheet{
+		Title:      randomText(r),
+		Performer:  randomText(r),
+		SongWriter: randomText(r),
+		Composer:   randomText(r),
+		Arranger:   randomText(r),
+		Message:    randomText(r),
+		Genre:      randomText(r),
+		DiscId:     randomText(r),
+		UpcEan:     randomText(r),
+		CdTextFile: randomText(r),
+	}
+	if r.Intn(2) == 0 {
+		c.Catalog = randomDigits(r, 13)
+	}
+	for i := r.Intn(4); i > 0; i-- {
+		c.Rem = append(c.Rem, remKeys[r.Intn(len(remKeys))]+" "+FormatString(randomText(r)))
+	}
+	if r.Intn(4) == 0 {
+		c.Pregap = Frame(r.Intn(1000))
+	}
+	if r.Intn(4) == 0 {
+		c.Postgap = Frame(r.Intn(1000))
+	}
+
+	trackNumber := uint(1)
+	for i := 1 + r.Intn(3); i > 0; i-- {
+		file := File{
+			FileName: randomText(r) + ".wav",
+			FileType: fileTypes[r.Intn(len(fileTypes))],
+			Tracks:   []Track{},
+		}
+		var frame Frame
+		for j := 1 + r.Intn(4); j > 0; j-- {
+			track := Track{
+				TrackNumber:   trackNumber,
+				TrackDataType: dataTypes[r.Intn(len(dataTypes))],
+				Flags:         Flags(r.Intn(16)) << 1,
+				Title:         randomText(r),
+				Performer:     randomText(r),
+				SongWriter:    randomText(r),
+				Composer:      randomText(r),
+				Arranger:      randomText(r),
+				Message:       randomText(r),
+			}
+			trackNumber++
+			if r.Intn(2) == 0 {
+				track.Isrc = "US" + randomDigits(r, 10)
+			}
+
+			// Pregap is either a PREGAP command, an INDEX 00, or absent
+			switch r.Intn(3) {
+			case 0:
+				track.Pregap = Frame(1 + r.Intn(300))
+			case 1:
+				track.Index = append(track.Index, TrackIndex{Number: 0, Frame: frame})
+				frame += Frame(1 + r.Intn(300))
+			}
+			for k := uint(1); k <= uint(1+r.Intn(3)); k++ {
+				track.Index = append(track.Index, TrackIndex{Number: k, Frame: frame})
+				frame += Frame(1 + r.Intn(20000))
+			}
+			if r.Intn(5) == 0 {
+				track.Postgap = Frame(1 + r.Intn(300))
+			}
+			file.Tracks = append(file.Tracks, track)
+		}
+		c.File = append(c.File, file)
+	}
+
+	return c
+}
+
+func TestRoundTripGenerated(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		original := randomCuesheet(r)
+
+		var sb strings.Builder
+		if err := WriteFile(&sb, &original); err != nil {
+			t.Fatalf("case %d: WriteFile error: %v", i, err)
+		}
+		readBack, err := ReadFile(strings.NewReader(sb.String()))
+		if err != nil {
+			t.Fatalf("case %d: ReadFile error: %v\n%s", i, err, sb.String())
+		}
+		if !reflect.DeepEqual(original, *readBack) {
+			t.Fatalf("case %d: round-trip mismatch\noriginal:  %+v\nread back: %+v\nwritten:\n%s", i, original, *readBack, sb.String())
+		}
+	}
+}
+
+func TestLBAConversion(t *testing.T) {
+	tests := []struct {
+		frame Frame
+		lba   int64
+	}{
+		{0, -150},
+		{149, -1},
+		{150, 0},
+		{151, 1},
+		{4500, 4350},
+	}
+	for _, tt := range tests {
+		if lba := tt.frame.ToLBA(); lba != tt.lba {
+			t.Errorf("Frame(%d).ToLBA() = %d, expected %d", tt.frame, lba, tt.lba)
+		}
+		if frame := LBAToFrame(tt.lba); frame != tt.frame {
+			t.Errorf("LBAToFrame(%d) = %d, expected %d", tt.lba, frame, tt.frame)
+		}
+	}
+
+	if frame := LBAToFrame(-151); frame != 0 {
+		t.Errorf("expected LBA before the lead-in to clamp to frame 0, got: %d", frame)
+	}
+}
+
+func TestTotalSectors(t *testing.T) {
+	var c Cuesheet
+
+	// A full 74-minute disc ends at MSF 74:00:00, lead-out LBA 332850
+	if sectors := c.TotalSectors(332850); sectors != 74*60*75 {
+		t.Errorf("expected %d sectors for a 74-minute disc, got: %d", 74*60*75, sectors)
+	}
+	if sectors := c.TotalSectors(0); sectors != LeadInFrames {
+		t.Errorf("expected an empty disc to span the lead-in only, got: %d", sectors)
+	}
+
+	sheet, err := ParseString("FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n" +
+		"  TRACK 02 AUDIO\n    PREGAP 00:02:00\n    INDEX 01 03:00:00\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without a leadout the sheet reaches track 02 plus its PREGAP
+	if sectors, expected := sheet.TotalSectors(0), int64(3*60*75+150+LeadInFrames); sectors != expected {
+		t.Errorf("expected %d sectors estimated from the sheet, got: %d", expected, sectors)
+	}
+	if sectors := sheet.TotalSectors(332850); sectors != 74*60*75 {
+		t.Errorf("expected the given leadout to be used, got: %d", sectors)
+	}
+}
+
+func TestFileBaseName(t *testing.T) {
+	tests := []struct {
+		fileName string
+		expected string
+	}{
+		{"album.wav", "album.wav"},
+		{`D:\Music\album.wav`, "album.wav"},
+		{`..\rips\01 - Track.flac`, "01 - Track.flac"},
+		{"/home/user/music/album.flac", "album.flac"},
+		{"music/sub dir/album.ape", "album.ape"},
+		{`mixed/path\album.wv`, "album.wv"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		file := File{FileName: tt.fileName}
+		if result := file.BaseName(); result != tt.expected {
+			t.Errorf("BaseName(%q) = %q, expected %q", tt.fileName, result, tt.expected)
+		}
+	}
+}
+
+func TestGapOrdering(t *testing.T) {
+	cuesheet := Cuesheet{
+		File: []File{
+			{
+				FileName: "test.wav",
+				FileType: "WAVE",
+				Tracks: []Track{
+					{
+						TrackNumber:   1,
+						TrackDataType: "AUDIO",
+						Pregap:        150,
+						Postgap:       75,
+						Index: []TrackIndex{
+							{Number: 1, Frame: 0},
+							{Number: 2, Frame: 750},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var sb strings.Builder
+	if err := WriteFile(&sb, &cuesheet); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+
+	pregap := strings.Index(out, "PREGAP")
+	firstIndex := strings.Index(out, "INDEX 01")
+	lastIndex := strings.Index(out, "INDEX 02")
+	postgap := strings.Index(out, "POSTGAP")
+	if pregap < 0 || firstIndex < 0 || lastIndex < 0 || postgap < 0 {
+		t.Fatalf("missing expected lines in output:\n%s", out)
+	}
+	if pregap > firstIndex {
+		t.Errorf("expected PREGAP before the first INDEX:\n%s", out)
+	}
+	if postgap < lastIndex {
+		t.Errorf("expected POSTGAP after the last INDEX:\n%s", out)
+	}
+}
+
+func TestValidatePregapConflict(t *testing.T) {
+	input := `FILE "test.wav" WAVE
+  TRACK 01 AUDIO
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    PREGAP 00:02:00
+    INDEX 00 03:00:00
+    INDEX 01 03:02:00
+`
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+
+	errs := cuesheet.Validate()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got: %v", errs)
+	}
+	var trackErr *TrackError
+	if !errors.As(errs[0], &trackErr) || trackErr.TrackNumber != 2 {
+		t.Errorf("expected error for track 2, got: %v", errs[0])
+	}
+	if !errors.Is(errs[0], ErrPregapConflict) {
+		t.Errorf("expected ErrPregapConflict, got: %v", errs[0])
+	}
+
+	track, _ := cuesheet.GetTrack(2)
+	track.Pregap = 0
+	if errs := cuesheet.Validate(); len(errs) != 0 {
+		t.Errorf("expected no errors with INDEX 00 only, got: %v", errs)
+	}
+}
+
+func TestValidateTrackModes(t *testing.T) {
+	layout := func(types ...string) *Cuesheet {
+		file := File{FileName: "image.bin", FileType: "BINARY"}
+		for i, typ := range types {
+			file.Tracks = append(file.Tracks, Track{
+				TrackNumber:   uint(i + 1),
+				TrackDataType: typ,
+				Index:         []TrackIndex{{Number: 1, Frame: Frame(i * 4500)}},
+			})
+		}
+		return &Cuesheet{File: []File{file}}
+	}
+
+	t.Run("MixedMode", func(t *testing.T) {
+		if errs := layout("MODE1/2352", "AUDIO", "AUDIO").ValidateTrackModes(); len(errs) != 0 {
+			t.Errorf("expected no warnings for leading data track, got: %v", errs)
+		}
+	})
+
+	t.Run("EnhancedCD", func(t *testing.T) {
+		if errs := layout("AUDIO", "AUDIO", "MODE2/2352").ValidateTrackModes(); len(errs) != 0 {
+			t.Errorf("expected no warnings for trailing data track, got: %v", errs)
+		}
+	})
+
+	t.Run("Interleaved", func(t *testing.T) {
+		errs := layout("AUDIO", "MODE2/2352", "AUDIO", "MODE1/2048", "AUDIO", "MODE1/2048").ValidateTrackModes()
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 warnings, got: %v", errs)
+		}
+		for i, expected := range []uint{2, 4} {
+			var trackErr *TrackError
+			if !errors.As(errs[i], &trackErr) || trackErr.TrackNumber != expected || !errors.Is(errs[i], ErrInterleavedDataTrack) {
+				t.Errorf("expected interleaved warning for track %d, got: %v", expected, errs[i])
+			}
+		}
+	})
+}
+
+func TestCatalogEAN(t *testing.T) {
+	tests := []struct {
+		catalog string
+		valid   bool
+	}{
+		{"1234567890128", true},
+		{"4006381333931", true},
+		{"1234567890123", false}, // wrong check digit
+		{"123456789012", false},  // too short
+		{"12345678901AB", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		cuesheet := Cuesheet{Catalog: tt.catalog}
+		value, ok := cuesheet.CatalogEAN()
+		if ok != tt.valid {
+			t.Errorf("CatalogEAN(%q) ok = %v, expected %v", tt.catalog, ok, tt.valid)
+		}
+		if ok && value != tt.catalog {
+			t.Errorf("CatalogEAN(%q) = %q", tt.catalog, value)
+		}
+		if !ok && value != "" {
+			t.Errorf("expected empty value for invalid catalog %q, got %q", tt.catalog, value)
+		}
+	}
+}
+
+func TestTrackSongWriter(t *testing.T) {
+	input := `SONGWRITER "Album Writer"
+FILE "test.wav" WAVE
+  TRACK 01 AUDIO
+    SONGWRITER "Track Writer"
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    INDEX 01 03:00:00
+`
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+
+	track1, _ := cuesheet.GetTrack(1)
+	if sw := cuesheet.TrackSongWriter(track1); sw != "Track Writer" {
+		t.Errorf("expected 'Track Writer', got: '%s'", sw)
+	}
+	track2, _ := cuesheet.GetTrack(2)
+	if sw := cuesheet.TrackSongWriter(track2); sw != "Album Writer" {
+		t.Errorf("expected fallback to 'Album Writer', got: '%s'", sw)
+	}
+}
+
+func TestSplitPerformers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"Artist A", []string{"Artist A"}},
+		{"A feat. B", []string{"A", "B"}},
+		{"A Feat B", []string{"A", "B"}},
+		{"A ft. B", []string{"A", "B"}},
+		{"A featuring B & C", []string{"A", "B", "C"}},
+		{"A & B", []string{"A", "B"}},
+		{"A, B", []string{"A", "B"}},
+		{"A; B / C", []string{"A", "B", "C"}},
+		{"Sammy Davis, Jr. & Dean Martin", []string{"Sammy Davis, Jr.", "Dean Martin"}},
+		{"Featherweight", []string{"Featherweight"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if result := SplitPerformers(tt.input); !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("SplitPerformers(%q) = %q, expected %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestUnquotedTextOption(t *testing.T) {
+	input := `TITLE Some Album WAVE
+PERFORMER The Band
+FILE "test.wav" WAVE
+  TRACK 01 AUDIO
+    TITLE First Song Title
+    PERFORMER "Quoted Artist" extra
+    INDEX 01 00:00:00
+`
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	if cuesheet.Title != "Some" {
+		t.Errorf("expected default parsing to keep only the first word, got: '%s'", cuesheet.Title)
+	}
+
+	cuesheet, err = ReadFileWithOptions(strings.NewReader(input), ReadOptions{UnquotedText: true})
+	if err != nil {
+		t.Fatalf("ReadFileWithOptions error: %v", err)
+	}
+	if cuesheet.Title != "Some Album WAVE" {
+		t.Errorf("expected 'Some Album WAVE', got: '%s'", cuesheet.Title)
+	}
+	if cuesheet.Performer != "The Band" {
+		t.Errorf("expected 'The Band', got: '%s'", cuesheet.Performer)
+	}
+	track := cuesheet.File[0].Tracks[0]
+	if track.Title != "First Song Title" {
+		t.Errorf("expected 'First Song Title', got: '%s'", track.Title)
+	}
+	if track.Performer != "Quoted Artist" {
+		t.Errorf("expected quoted value to be read as usual, got: '%s'", track.Performer)
+	}
+}
+
+func TestCdTextFile(t *testing.T) {
+	original := Cuesheet{
+		Title:      "Album",
+		CdTextFile: "album.cdt",
+		File: []File{
+			{
+				FileName: "album.wav",
+				FileType: "WAVE",
+				Tracks: []Track{
+					{TrackNumber: 1, TrackDataType: "AUDIO", Index: []TrackIndex{{Number: 1, Frame: 0}}},
+				},
+			},
+		},
+	}
+
+	var sb strings.Builder
+	if err := WriteFile(&sb, &original); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	cdtext := strings.Index(out, "CDTEXTFILE album.cdt")
+	if cdtext < 0 || cdtext > strings.Index(out, "FILE album.wav") {
+		t.Errorf("expected CDTEXTFILE before the first FILE:\n%s", out)
+	}
+
+	readBack, err := ReadFile(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(original, *readBack) {
+		t.Errorf("round-trip mismatch: %+v", *readBack)
+	}
+
+	t.Run("CheckFiles", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(dir+"/album.wav", []byte("dummy"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		errs := original.CheckFiles(dir)
+		if len(errs) != 1 || !os.IsNotExist(errs[0]) || !strings.Contains(errs[0].Error(), "album.cdt") {
+			t.Fatalf("expected missing CDTEXTFILE error, got: %v", errs)
+		}
+		result := original.ValidateDetailedInDir(dir)
+		if len(result.Errors) != 1 || result.Errors[0].Code != IssueMissingFile || result.Errors[0].FileIndex != -1 {
+			t.Fatalf("expected a missing-file issue for the CDTEXTFILE, got: %+v", result.Errors)
+		}
+		if !original.ValidateDetailed().Valid() {
+			t.Error("expected ValidateDetailed not to check files")
+		}
+
+		if err := os.WriteFile(dir+"/album.cdt", []byte("dummy"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if errs := original.CheckFiles(dir); len(errs) != 0 {
+			t.Errorf("expected no errors, got: %v", errs)
+		}
+		if result := original.ValidateDetailedInDir(dir); !result.Valid() {
+			t.Errorf("expected no issues, got: %+v", result.Errors)
+		}
+	})
+}
+
+func TestRawRemValue(t *testing.T) {
+	input := "REM COMMENT \"He said \"hello\" twice\"\nREM DATE 2024\nFILE \"test.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, ok := cuesheet.GetRawRemByKey("comment")
+	if !ok || raw != `"He said "hello" twice"` {
+		t.Errorf("expected raw comment with all quotes, got: %q", raw)
+	}
+	if value, _ := cuesheet.GetRemByKey("COMMENT"); value == raw {
+		t.Errorf("expected GetRemByKey to strip quotes, got: %q", value)
+	}
+
+	if raw, ok := cuesheet.GetRawRemByKey("DATE"); !ok || raw != "2024" {
+		t.Errorf("expected raw date '2024', got: %q", raw)
+	}
+	if _, ok := cuesheet.GetRawRemByKey("GENRE"); ok {
+		t.Error("expected missing key to report false")
+	}
+}
+
+func TestMixedQuotes(t *testing.T) {
+	input := "TITLE \"It's a Test\"\nPERFORMER 'The \"Quoted\" Band'\nFILE \"test.wav\" WAVE\n  TRACK 01 AUDIO\n    TITLE \"Don't Stop\"\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cuesheet.Title != "It's a Test" {
+		t.Errorf("expected title \"It's a Test\", got: %q", cuesheet.Title)
+	}
+	if cuesheet.Performer != `The "Quoted" Band` {
+		t.Errorf("expected performer 'The \"Quoted\" Band', got: %q", cuesheet.Performer)
+	}
+	if title := cuesheet.File[0].Tracks[0].Title; title != "Don't Stop" {
+		t.Errorf("expected track title \"Don't Stop\", got: %q", title)
+	}
+
+	var sb strings.Builder
+	if err := WriteFile(&sb, cuesheet); err != nil {
+		t.Fatal(err)
+	}
+	readBack, err := ReadFile(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cuesheet, readBack) {
+		t.Errorf("round-trip mismatch:\n%s", sb.String())
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	input := "REM DISCID 7e07210a\nREM COMMENT \"lower case stays\"\nDISC_ID abc123ff\nFILE \"test.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cuesheet.DiscId != "abc123ff" {
+		t.Errorf("expected raw disc id to be preserved, got: %q", cuesheet.DiscId)
+	}
+
+	cuesheet.Canonicalize()
+	if cuesheet.DiscId != "ABC123FF" {
+		t.Errorf("expected DISC_ID 'ABC123FF', got: %q", cuesheet.DiscId)
+	}
+	if discID, _ := cuesheet.GetRemByKey("DISCID"); discID != "7E07210A" {
+		t.Errorf("expected REM DISCID '7E07210A', got: %q", discID)
+	}
+	if comment, _ := cuesheet.GetRemByKey("COMMENT"); comment != "lower case stays" {
+		t.Errorf("expected other REM fields untouched, got: %q", comment)
+	}
+}
+
+func TestCanonicalizeReplayGain(t *testing.T) {
+	a := Cuesheet{Rem: []string{"REPLAYGAIN_ALBUM_GAIN -6.2 dB", "REPLAYGAIN_ALBUM_PEAK 0.98", "REPLAYGAIN_ALGORITHM \"EBU R128\""}}
+	b := Cuesheet{Rem: []string{"REPLAYGAIN_ALBUM_GAIN -6.20 dB", "REPLAYGAIN_ALBUM_PEAK 0.980000", "REPLAYGAIN_ALGORITHM \"EBU R128\""}}
+	if reflect.DeepEqual(a, b) {
+		t.Fatal("expected raw values to differ before canonicalization")
+	}
+
+	a.Canonicalize()
+	b.Canonicalize()
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected equal sheets after canonicalization, got:\n%q\n%q", a.Rem, b.Rem)
+	}
+	if gain, _ := a.GetRemValue(RemReplayGainAlbumGain); gain != "-6.20 dB" {
+		t.Errorf("expected gain '-6.20 dB', got: %q", gain)
+	}
+
+	bad := Cuesheet{Rem: []string{"REPLAYGAIN_TRACK_GAIN n/a"}}
+	bad.Canonicalize()
+	if bad.Rem[0] != "REPLAYGAIN_TRACK_GAIN n/a" {
+		t.Errorf("expected non-numeric value untouched, got: %q", bad.Rem[0])
+	}
+
+	tracks := Cuesheet{File: []File{{Tracks: []Track{{Rem: []string{"REPLAYGAIN_TRACK_GAIN -3.1 dB", "REPLAYGAIN_TRACK_PEAK 0.5"}}}}}}
+	tracks.Canonicalize()
+	if rem := tracks.File[0].Tracks[0].Rem; !reflect.DeepEqual(rem, []string{"REPLAYGAIN_TRACK_GAIN -3.10 dB", "REPLAYGAIN_TRACK_PEAK 0.500000"}) {
+		t.Errorf("expected track ReplayGain values to be canonicalized, got: %q", rem)
+	}
+}
+
+func TestSkipPreamble(t *testing.T) {
+	input := "1\nPREGAP listing generated by SomeRipper\nTITLE \"Album\"\nFILE \"test.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n"
+
+	if _, err := ReadFile(strings.NewReader(input)); err == nil {
+		t.Error("expected junk PREGAP line to fail without SkipPreamble")
+	}
+
+	var warnings []string
+	opts := ReadOptions{
+		SkipPreamble: true,
+		Warn:         func(msg string) { warnings = append(warnings, msg) },
+	}
+	cuesheet, err := ReadFileWithOptions(strings.NewReader(input), opts)
+	if err != nil {
+		t.Fatalf("ReadFileWithOptions failed: %v", err)
+	}
+	if cuesheet.Title != "Album" || cuesheet.TrackCount() != 1 {
+		t.Errorf("unexpected cuesheet: %+v", cuesheet)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "skipped 2 line(s)") {
+		t.Errorf("expected a warning about 2 skipped lines, got: %q", warnings)
+	}
+
+	warnings = nil
+	if _, err := ReadFileWithOptions(strings.NewReader("TITLE \"Album\"\n"), opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings for a clean sheet, got: %q", warnings)
+	}
+}
+
+func TestWriteEmptyCuesheet(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteFile(&sb, &Cuesheet{}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != "\n" {
+		t.Errorf("expected a single empty line, got: %q", sb.String())
+	}
+
+	readBack, err := ReadFile(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*readBack, Cuesheet{}) {
+		t.Errorf("expected empty cuesheet after round-trip, got: %+v", readBack)
+	}
+
+	sb.Reset()
+	if err := WriteFile(&sb, &Cuesheet{Rem: []string{"COMMENT \"last\"\r\n"}}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != "REM COMMENT \"last\"\n" {
+		t.Errorf("expected exactly one trailing newline, got: %q", sb.String())
+	}
+}
+
+func TestPlayingDuration(t *testing.T) {
+	input := "FILE \"album.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 00 03:00:00\n    INDEX 01 03:02:00\n  TRACK 03 AUDIO\n    INDEX 01 06:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracks := cuesheet.File[0].Tracks
+
+	if d := tracks[0].PlayingDuration(&tracks[1]); d != 3*time.Minute {
+		t.Errorf("expected track 1 to end at the next INDEX 00 (3m0s), got: %v", d)
+	}
+	if d := tracks[0].Duration(tracks[1].Index[1].Frame); d != 3*time.Minute+2*time.Second {
+		t.Errorf("expected Duration to the next INDEX 01 to include the pregap, got: %v", d)
+	}
+	if d := tracks[1].PlayingDuration(&tracks[2]); d != 2*time.Minute+58*time.Second {
+		t.Errorf("expected track 2 to end at the next INDEX 01 (2m58s), got: %v", d)
+	}
+}
+
+func TestIsComplete(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		message string
+	}{
+		{"Complete", "FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n", ""},
+		{"TruncatedAfterTrack", "FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n", "track 02 has no INDEX 01"},
+		{"TruncatedAfterFile", "TITLE \"Album\"\nFILE \"a.wav\" WAVE\n", "FILE \"a.wav\" has no tracks"},
+		{"NoFile", "TITLE \"Album\"\n", "no FILE entries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cuesheet, err := ReadFile(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, message := cuesheet.IsComplete()
+			if ok != (tt.message == "") || message != tt.message {
+				t.Errorf("IsComplete() = (%v, %q), expected message %q", ok, message, tt.message)
+			}
+		})
+	}
+}
+
+func TestHasRemKey(t *testing.T) {
+	input := "REM COMPILATION\nREM COMMENT \"\"\nFILE test.wav WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cuesheet.HasRemKey("compilation") {
+		t.Error("expected value-less REM COMPILATION to be present")
+	}
+	if value, _ := cuesheet.GetRemByKey("COMPILATION"); value != "" {
+		t.Errorf("expected empty value, got: %q", value)
+	}
+	if !cuesheet.HasRemKey("COMMENT") {
+		t.Error("expected REM COMMENT with an empty value to be present")
+	}
+	if cuesheet.HasRemKey("GENRE") {
+		t.Error("expected missing REM GENRE to be absent")
+	}
+
+	var sb strings.Builder
+	if err := WriteFile(&sb, cuesheet); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != input {
+		t.Errorf("round-trip mismatch:\n%s", sb.String())
+	}
+}
+
+func TestFrameScale(t *testing.T) {
+	const pal FrameScale = 25
+
+	input := "FILE video.mkv MP3\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 01 01:00:24\n"
+	cuesheet, err := ReadFileWithOptions(strings.NewReader(input), ReadOptions{FrameScale: pal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := cuesheet.File[0].Tracks[1].Index[0].Frame
+	if frame != 60*25+24 {
+		t.Errorf("expected frame %d at 25 fps, got: %d", 60*25+24, frame)
+	}
+	if d := pal.ToDuration(frame); d != 60*time.Second+960*time.Millisecond {
+		t.Errorf("expected 1m0.96s, got: %v", d)
+	}
+	if f := pal.DurationToFrame(2 * time.Second); f != 50 {
+		t.Errorf("expected 50 frames for 2s at 25 fps, got: %d", f)
+	}
+
+	var sb strings.Builder
+	if err := WriteFileWithOptions(&sb, cuesheet, WriteOptions{FrameScale: pal}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != input {
+		t.Errorf("round-trip mismatch at 25 fps:\n%s", sb.String())
+	}
+
+	if FormatFrame(frame) != "00:20:24" || pal.FormatFrame(frame) != "01:00:24" {
+		t.Errorf("unexpected formatting: CD %s, 25 fps %s", FormatFrame(frame), pal.FormatFrame(frame))
+	}
+	if FrameScale(0).ToDuration(75) != time.Second {
+		t.Error("expected the zero scale to default to CD frames")
+	}
+}
+
+func TestByteOrderMarks(t *testing.T) {
+	input := "\ufeffTITLE \"Album\"\nFILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n\ufeff  TRACK 02 AUDIO\n    INDEX 01 03:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cuesheet.Title != "Album" {
+		t.Errorf("expected title 'Album' after a leading BOM, got: %q", cuesheet.Title)
+	}
+	if cuesheet.TrackCount() != 2 {
+		t.Errorf("expected 2 tracks with a BOM before the second TRACK, got: %d", cuesheet.TrackCount())
+	}
+
+	for name, bom := range map[string]string{"UTF16LE": "\xff\xfe", "UTF16BE": "\xfe\xff"} {
+		if _, err := ReadFile(strings.NewReader(bom + "T\x00I\x00")); !errors.Is(err, ErrUTF16) {
+			t.Errorf("%s: expected ErrUTF16, got: %v", name, err)
+		}
+	}
+}
+
+func TestDuplicateFields(t *testing.T) {
+	input := "REM GENRE \"Rock\"\nREM COMPOSER \"Someone Else\"\nREM DATE 1971\nGENRE \"Rock\"\nCOMPOSER \"Billy Joel\"\nFILE test.wav WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n"
+	read := func() *Cuesheet {
+		cuesheet, err := ReadFile(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cuesheet
+	}
+
+	cuesheet := read()
+	dups := cuesheet.DuplicateFields()
+	expected := []DuplicateField{
+		{Key: "GENRE", CdTextValue: "Rock", RemValue: "Rock"},
+		{Key: "COMPOSER", CdTextValue: "Billy Joel", RemValue: "Someone Else"},
+	}
+	if !reflect.DeepEqual(dups, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, dups)
+	}
+	if dups[0].Conflict() || !dups[1].Conflict() {
+		t.Error("expected only COMPOSER to conflict")
+	}
+
+	t.Run("DropRem", func(t *testing.T) {
+		cuesheet := read()
+		conflicts := cuesheet.RemoveDuplicateFields(false)
+		if len(conflicts) != 1 || conflicts[0].Key != "COMPOSER" {
+			t.Errorf("expected the COMPOSER conflict, got: %+v", conflicts)
+		}
+		if cuesheet.HasRemKey("GENRE") || cuesheet.Genre != "Rock" {
+			t.Errorf("expected REM GENRE removed and CD-TEXT GENRE kept, got: %q / %q", cuesheet.Rem, cuesheet.Genre)
+		}
+		if !cuesheet.HasRemKey("COMPOSER") || !cuesheet.HasRemKey("DATE") {
+			t.Errorf("expected conflicting and unrelated REM fields kept, got: %q", cuesheet.Rem)
+		}
+	})
+
+	t.Run("KeepRem", func(t *testing.T) {
+		cuesheet := read()
+		cuesheet.RemoveDuplicateFields(true)
+		if !cuesheet.HasRemKey("GENRE") || cuesheet.Genre != "" {
+			t.Errorf("expected CD-TEXT GENRE cleared and REM GENRE kept, got: %q / %q", cuesheet.Rem, cuesheet.Genre)
+		}
+		if cuesheet.Composer != "Billy Joel" {
+			t.Errorf("expected conflicting CD-TEXT COMPOSER kept, got: %q", cuesheet.Composer)
+		}
+	})
+}
+
+func TestValidateTrackLengths(t *testing.T) {
+	input := "FILE \"image.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 01 03:00:00\n  TRACK 03 AUDIO\n    INDEX 01 03:01:50\n  TRACK 04 AUDIO\n    INDEX 01 03:01:60\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := cuesheet.ValidateTrackLengths(MinTrackLength)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 short tracks, got: %v", errs)
+	}
+	var short *ShortTrackError
+	if !errors.As(errs[0], &short) || short.TrackNumber != 2 || short.Duration != Frame(125).ToDuration() {
+		t.Errorf("expected track 02 to be short at 1.666s, got: %v", errs[0])
+	}
+	if !strings.Contains(errs[1].Error(), "track 03") {
+		t.Errorf("expected track 03 in message, got: %v", errs[1])
+	}
+
+	if errs := cuesheet.ValidateTrackLengths(200 * time.Millisecond); len(errs) != 1 {
+		t.Errorf("expected only track 03 below 200ms, got: %v", errs)
+	}
+
+	noStart, err := ReadFile(strings.NewReader("FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 00 00:00:00\n  TRACK 02 AUDIO\n    INDEX 01 04:00:00\n  TRACK 03 AUDIO\n    INDEX 01 08:00:00\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := noStart.ValidateTrackLengths(MinTrackLength); len(errs) != 0 {
+		t.Errorf("expected a track without INDEX 01 not to be reported as short, got: %v", errs)
+	}
+}
+
+func TestValidateIndexBounds(t *testing.T) {
+	input := "FILE \"short.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 00 02:58:00\n    INDEX 01 03:10:00\nFILE \"other.wav\" WAVE\n  TRACK 03 AUDIO\n    INDEX 01 09:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := cuesheet.ValidateIndexBounds(map[string]time.Duration{"short.wav": 3 * time.Minute})
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 out-of-bounds index, got: %v", errs)
+	}
+	var boundsErr *IndexBoundsError
+	if !errors.As(errs[0], &boundsErr) || boundsErr.TrackNumber != 2 || boundsErr.IndexNumber != 1 || boundsErr.FileName != "short.wav" {
+		t.Errorf("expected track 02 INDEX 01 of short.wav, got: %v", errs[0])
+	}
+
+	if errs := cuesheet.ValidateIndexBounds(map[string]time.Duration{"short.wav": 4 * time.Minute}); len(errs) != 0 {
+		t.Errorf("expected no errors for a long enough file, got: %v", errs)
+	}
+}
+
+func TestTrackRem(t *testing.T) {
+	input := "FILE test.wav WAVE\n" +
+		"  TRACK 01 AUDIO\n    TITLE One\n    REM REPLAYGAIN_TRACK_GAIN -7.25 dB\n    REM REPLAYGAIN_TRACK_PEAK 0.988\n    INDEX 01 00:00:00\n" +
+		"  TRACK 02 AUDIO\n    INDEX 00 03:00:00\n    REM COMMENT \"between indexes\"\n    INDEX 01 03:02:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	track1 := &cuesheet.File[0].Tracks[0]
+	expected := []string{"REPLAYGAIN_TRACK_GAIN -7.25 dB", "REPLAYGAIN_TRACK_PEAK 0.988"}
+	if !reflect.DeepEqual(track1.Rem, expected) {
+		t.Errorf("expected track REMs %q, got %q", expected, track1.Rem)
+	}
+	if gain, ok := track1.GetRemValue(RemReplayGainTrackGain); !ok || gain != "-7.25 dB" {
+		t.Errorf("expected track gain '-7.25 dB', got: %q", gain)
+	}
+	track2 := &cuesheet.File[0].Tracks[1]
+	if len(track2.Rem) != 1 || len(track2.Index) != 2 {
+		t.Errorf("expected the REM between indexes to be captured, got: %+v", track2)
+	}
+	if cuesheet.Rem != nil {
+		t.Errorf("expected no album REMs, got: %q", cuesheet.Rem)
+	}
+
+	var sb strings.Builder
+	if err := WriteFile(&sb, cuesheet); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "    TITLE One\n    REM REPLAYGAIN_TRACK_GAIN -7.25 dB\n    REM REPLAYGAIN_TRACK_PEAK 0.988\n    INDEX 01") {
+		t.Errorf("expected indented track REMs in order:\n%s", sb.String())
+	}
+	readBack, err := ReadFile(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cuesheet, readBack) {
+		t.Errorf("round-trip mismatch:\n%s", sb.String())
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		line    int
+		column  int
+		command string
+	}{
+		{"Index", "TITLE \"Album\"\nFILE a.wav WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 01 3:xx\n", 6, 5, "INDEX"},
+		{"TrackNumber", "FILE a.wav WAVE\n  TRACK one AUDIO\n", 2, 3, "TRACK"},
+		{"AlbumPregap", "REM DATE 2000\nPREGAP 00:02\n", 2, 1, "PREGAP"},
+		{"TrackPostgap", "FILE a.wav WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n    POSTGAP bad\n", 4, 5, "POSTGAP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadFile(strings.NewReader(tt.input))
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected a ParseError, got: %v", err)
+			}
+			if parseErr.Line != tt.line || parseErr.Column != tt.column || parseErr.Command != tt.command {
+				t.Errorf("expected %s at %d:%d, got %s at %d:%d", tt.command, tt.line, tt.column,
+					parseErr.Command, parseErr.Line, parseErr.Column)
+			}
+			if !strings.HasPrefix(err.Error(), "cue parse error at line ") {
+				t.Errorf("unexpected message: %v", err)
+			}
+		})
+	}
+
+	_, err := ReadFile(strings.NewReader("PREGAP 00:02\n"))
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("expected errors.Is(err, strconv.ErrSyntax) through the ParseError, got: %v", err)
+	}
+}
+
+func TestCustomRemRoundTrip(t *testing.T) {
+	input := "REM CUSTOM \"a b\"\n" +
+		"REM CUSTOM2 value\n" +
+		"REM CUSTOM3 'single quoted'\n" +
+		"REM CUSTOM4 unquoted  with  spaces\n" +
+		"REM CUSTOM5 \"say \\\"hi\\\"\"\n" +
+		"FILE test.wav WAVE\n" +
+		"  TRACK 01 AUDIO\n" +
+		"    REM TRACK_CUSTOM \"x y\"\n" +
+		"    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, expected := range map[string]string{"CUSTOM": "a b", "CUSTOM2": "value", "CUSTOM3": "single quoted"} {
+		if value, ok := cuesheet.GetRemByKey(key); !ok || value != expected {
+			t.Errorf("GetRemByKey(%q) = %q, expected %q", key, value, expected)
+		}
+	}
+
+	var sb strings.Builder
+	if err := WriteFile(&sb, cuesheet); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != input {
+		t.Errorf("expected custom REM lines byte-for-byte, got:\n%s", sb.String())
+	}
+}
+
+func TestCrossfadePoints(t *testing.T) {
+	input := "FILE mix.wav WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 00 03:00:00\n    INDEX 01 03:02:00\n    INDEX 03 06:30:00\n    INDEX 02 06:10:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := cuesheet.CrossfadePoints(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Frame{DurationToFrame(370 * time.Second), DurationToFrame(390 * time.Second)}
+	if !reflect.DeepEqual(points, expected) {
+		t.Errorf("expected %v, got %v", expected, points)
+	}
+
+	if points, err := cuesheet.CrossfadePoints(1); err != nil || len(points) != 0 {
+		t.Errorf("expected no points for track 1, got: %v, %v", points, err)
+	}
+	if _, err := cuesheet.CrossfadePoints(3); !errors.Is(err, ErrTrackNotFound) {
+		t.Errorf("expected ErrTrackNotFound, got: %v", err)
+	}
+}
+
+func TestWriteLineEnding(t *testing.T) {
+	input := "REM DATE 1971\r\nTITLE \"My Album\"\r\nFILE \"my album.wav\" WAVE\r\n  TRACK 01 AUDIO\r\n    TITLE \"Track One\"\r\n    INDEX 01 00:00:00\r\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cuesheet.Rem[0] != "DATE 1971" || cuesheet.Title != "My Album" {
+		t.Errorf("expected CR to be trimmed from values, got: %q, %q", cuesheet.Rem[0], cuesheet.Title)
+	}
+
+	var sb strings.Builder
+	if err := WriteFileWithOptions(&sb, cuesheet, WriteOptions{LineEnding: "\r\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != input {
+		t.Errorf("expected byte-for-byte CRLF round-trip, got: %q", sb.String())
+	}
+
+	sb.Reset()
+	if err := WriteFile(&sb, cuesheet); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != strings.ReplaceAll(input, "\r\n", "\n") {
+		t.Errorf("expected LF output by default, got: %q", sb.String())
+	}
+
+	sb.Reset()
+	if err := WriteFileWithOptions(&sb, &Cuesheet{}, WriteOptions{LineEnding: "\r\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != "\r\n" {
+		t.Errorf("expected an empty cuesheet to be a single CRLF, got: %q", sb.String())
+	}
+}
+
+func TestWriteIndent(t *testing.T) {
+	input := "REM DATE 1971\nTITLE \"My Album\"\nFILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    TITLE \"One\"\n    REM COMPOSER Someone\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    TITLE \"Two\"\n    INDEX 00 03:58:00\n    INDEX 01 04:00:00\nREM COMMENT \"between files\"\nFILE \"b.wav\" WAVE\n  TRACK 03 AUDIO\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		opts   WriteOptions
+		prefix string
+	}{
+		{"Tabs", WriteOptions{TrackIndent: "\t", FieldIndent: "\t\t"}, "\tTRACK 01 AUDIO\n\t\tTITLE One\n"},
+		{"Unindented", WriteOptions{Unindented: true}, "TRACK 01 AUDIO\nTITLE One\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := WriteFileWithOptions(&sb, cuesheet, tc.opts); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(sb.String(), tc.prefix) {
+				t.Errorf("expected output to contain %q, got:\n%s", tc.prefix, sb.String())
+			}
+			back, err := ReadFile(strings.NewReader(sb.String()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(back, cuesheet) {
+				t.Errorf("round-trip mismatch:\nwant %+v\ngot  %+v", cuesheet, back)
+			}
+		})
+	}
+}
+
+func TestReadTrackKeepsNestedUnknown(t *testing.T) {
+	input := "FILE \"a.wav\" WAVE\n\tTRACK 01 AUDIO\n\t\tVENDOR_TAG 42\n\t\tINDEX 01 00:00:00\nFILE \"b.wav\" WAVE\n\tTRACK 02 AUDIO\n\t\tINDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []RawLine{{"VENDOR_TAG 42", 1}}; !reflect.DeepEqual(cuesheet.Unknown, expected) {
+		t.Errorf("expected the nested unknown line to be kept, got: %+v", cuesheet.Unknown)
+	}
+	if len(cuesheet.File[0].Tracks[0].Index) != 1 {
+		t.Errorf("expected the INDEX after the unknown line to be parsed")
+	}
+
+	var sb strings.Builder
+	if err := WriteFile(&sb, cuesheet); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "INDEX 01 00:00:00\nVENDOR_TAG 42\nFILE b.wav") {
+		t.Errorf("expected the unknown line after the first FILE block, got:\n%s", sb.String())
+	}
+}
+
+func TestSingleDigitNumbers(t *testing.T) {
+	input := "FILE \"a.wav\" WAVE\n  TRACK 1 AUDIO\n    INDEX 1 00:00:00\n  TRACK 2 AUDIO\n    INDEX 0 03:58:00\n    INDEX 1 04:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracks := cuesheet.File[0].Tracks
+	if len(tracks) != 2 || tracks[1].TrackNumber != 2 || len(tracks[1].Index) != 2 || tracks[1].Index[0].Number != 0 {
+		t.Fatalf("unexpected tracks: %+v", tracks)
+	}
+
+	var sb strings.Builder
+	if err := WriteFile(&sb, cuesheet); err != nil {
+		t.Fatal(err)
+	}
+	expected := "FILE a.wav WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 00 03:58:00\n    INDEX 01 04:00:00\n"
+	if sb.String() != expected {
+		t.Errorf("expected two-digit numbers on output, got:\n%s", sb.String())
+	}
+}
+
+func TestPreserveNumberFormatting(t *testing.T) {
+	input := "FILE a.wav WAVE\n  TRACK 1 AUDIO\n    INDEX 1 00:00:00\n  TRACK 02 AUDIO\n    INDEX 0 03:58:00\n    INDEX 001 04:00:00\n"
+	cuesheet, err := ReadFileWithOptions(strings.NewReader(input), ReadOptions{PreserveFormatting: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := WriteFileWithOptions(&sb, cuesheet, WriteOptions{PreserveFormatting: true}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != input {
+		t.Errorf("expected number formatting to be preserved, got:\n%s", sb.String())
+	}
+
+	sb.Reset()
+	if err := WriteFile(&sb, cuesheet); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "TRACK 01 AUDIO\n    INDEX 01 00:00:00\n") {
+		t.Errorf("expected two-digit numbers without PreserveFormatting, got:\n%s", sb.String())
+	}
+
+	plain, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.File[0].Tracks[0].NumberWidth != 0 || plain.File[0].Tracks[0].Index[0].NumberWidth != 0 {
+		t.Errorf("expected no widths to be recorded by default")
+	}
+}
+
+func TestTrackDurations(t *testing.T) {
+	input := `FILE "album.flac" WAVE
+  TRACK 01 AUDIO
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    INDEX 00 03:58:00
+    INDEX 01 04:00:00
+FILE "bonus-1.flac" WAVE
+  TRACK 03 AUDIO
+    INDEX 01 00:00:00
+FILE "bonus-2.flac" WAVE
+  TRACK 04 AUDIO
+    INDEX 01 00:02:00
+`
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := cuesheet.File[0].TrackDurations(DurationToFrame(9 * time.Minute))
+	expected := []time.Duration{4 * time.Minute, 5 * time.Minute}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	// One file per track: each track runs to the end of its own file
+	got = cuesheet.TrackDurations(map[string]time.Duration{
+		"album.flac":   9 * time.Minute,
+		"bonus-1.flac": 3 * time.Minute,
+		"bonus-2.flac": 2 * time.Minute,
+	})
+	expected = []time.Duration{4 * time.Minute, 5 * time.Minute, 3 * time.Minute, 2*time.Minute - 2*time.Second}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	got = cuesheet.TrackDurations(nil)
+	if got[1] != 0 || got[0] != 4*time.Minute {
+		t.Errorf("expected unknown file lengths to give zero last-track durations, got %v", got)
+	}
+}
+
+func TestTotalDurationWithFileLengths(t *testing.T) {
+	t.Run("SingleFile", func(t *testing.T) {
+		cuesheet, err := ReadFile(strings.NewReader("FILE \"album.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 01 04:00:00\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := cuesheet.TotalDuration(); d != 4*time.Minute {
+			t.Errorf("expected the start of the last track without lengths, got: %v", d)
+		}
+		lengths := map[string]Frame{"album.wav": DurationToFrame(9 * time.Minute)}
+		if d := cuesheet.TotalDurationWithFileLengths(lengths); d != 9*time.Minute {
+			t.Errorf("expected 9m0s, got: %v", d)
+		}
+	})
+
+	t.Run("FilePerTrack", func(t *testing.T) {
+		cuesheet, err := ReadFile(strings.NewReader("FILE \"01.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\nFILE \"02.wav\" WAVE\n  TRACK 02 AUDIO\n    INDEX 00 00:00:00\n    INDEX 01 00:02:00\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lengths := map[string]Frame{
+			"01.wav": DurationToFrame(3 * time.Minute),
+			"02.wav": DurationToFrame(4 * time.Minute),
+		}
+		if d := cuesheet.TotalDurationWithFileLengths(lengths); d != 7*time.Minute {
+			t.Errorf("expected 7m0s, got: %v", d)
+		}
+		delete(lengths, "02.wav")
+		if d := cuesheet.TotalDurationWithFileLengths(lengths); d != 3*time.Minute+2*time.Second {
+			t.Errorf("expected an unknown file to count up to its last INDEX, got: %v", d)
+		}
+	})
+
+	t.Run("AbsoluteMultiFile", func(t *testing.T) {
+		cuesheet, err := ReadFile(strings.NewReader("FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 01 03:00:00\nFILE \"b.wav\" WAVE\n  TRACK 03 AUDIO\n    INDEX 01 05:00:00\n  TRACK 04 AUDIO\n    INDEX 01 08:00:00\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := cuesheet.IndexAddressing(); mode != AddressingAbsolute {
+			t.Fatalf("expected absolute addressing, got: %v", mode)
+		}
+		if d := cuesheet.TotalDuration(); d != 8*time.Minute {
+			t.Errorf("expected the largest disc position 8m0s, got: %v", d)
+		}
+		lengths := map[string]Frame{"a.wav": DurationToFrame(5 * time.Minute)}
+		if d := cuesheet.TotalDurationWithFileLengths(lengths); d != 8*time.Minute {
+			t.Errorf("expected a known first file not to be counted twice, got: %v", d)
+		}
+	})
+}
+
+func TestJoinContinuations(t *testing.T) {
+	input := "TITLE \"A Very Long Album Title That \\\n    Was Wrapped By A Tool\"\nFILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    PERFORMER \"Someone \\\r\n      Else\"\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 01 bad\n"
+
+	_, err := ReadFileWithOptions(strings.NewReader(input), ReadOptions{JoinContinuations: true})
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 9 {
+		t.Fatalf("expected a parse error on physical line 9, got: %v", err)
+	}
+
+	input = strings.Replace(input, "bad", "04:00:00", 1)
+	cuesheet, err := ReadFileWithOptions(strings.NewReader(input), ReadOptions{JoinContinuations: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cuesheet.Title != "A Very Long Album Title That Was Wrapped By A Tool" {
+		t.Errorf("expected the continued TITLE to be joined, got: %q", cuesheet.Title)
+	}
+	if performer := cuesheet.File[0].Tracks[0].Performer; performer != "Someone Else" {
+		t.Errorf("expected the continued PERFORMER to be joined, got: %q", performer)
+	}
+	if cuesheet.TrackCount() != 2 {
+		t.Errorf("expected 2 tracks, got: %d", cuesheet.TrackCount())
+	}
+
+	plain, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Title == cuesheet.Title {
+		t.Errorf("expected continuations to be left alone by default")
+	}
+}
+
+func TestResolveFilePaths(t *testing.T) {
+	input := "FILE \"subdir/01 - Intro.flac\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"Disc 2\\\\02 - Song.flac\" WAVE\n  TRACK 02 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"./extras/../03.flac\" WAVE\n  TRACK 03 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"/music/04.flac\" WAVE\n  TRACK 04 AUDIO\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join("library", "album")
+	expected := []string{
+		filepath.Join(dir, "subdir", "01 - Intro.flac"),
+		filepath.Join(dir, "Disc 2", "02 - Song.flac"),
+		filepath.Join(dir, "03.flac"),
+		filepath.FromSlash("/music/04.flac"),
+	}
+	if paths := cuesheet.ResolveFilePaths(dir); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %q, got %q", expected, paths)
+	}
+}
+
+func TestValidateFilePerTrack(t *testing.T) {
+	input := "FILE \"01.flac\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"02.flac\" WAVE\n  TRACK 02 AUDIO\n    INDEX 01 00:00:00\n  TRACK 03 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"03.flac\" WAVE\n" +
+		"FILE \"04.flac\" WAVE\n  TRACK 04 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"05.flac\" WAVE\n  TRACK 05 AUDIO\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := cuesheet.ValidateFilePerTrack()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got: %v", errs)
+	}
+	var countErr *FileTrackCountError
+	if !errors.As(errs[0], &countErr) || countErr.FileName != "02.flac" || countErr.Tracks != 2 {
+		t.Errorf("expected 02.flac to have 2 tracks, got: %v", errs[0])
+	}
+	if errs[1].Error() != "FILE 03.flac has 0 tracks, expected 1" {
+		t.Errorf("unexpected error: %v", errs[1])
+	}
+
+	file, err := os.Open("testdata/sample_2.cue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	sample, err := ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := sample.ValidateFilePerTrack(); len(errs) != 0 {
+		t.Errorf("expected no errors for sample_2, got: %v", errs)
+	}
+
+	image, err := ReadFile(strings.NewReader("FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 01 04:00:00\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := image.ValidateFilePerTrack(); len(errs) != 0 {
+		t.Errorf("expected single-file sheets not to be checked, got: %v", errs)
+	}
+}
+
+func TestQuotedFileType(t *testing.T) {
+	input := "FILE \"a.wav\" \"WAVE\"\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"b.mp3\" mp3\n  TRACK 02 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"c.aiff\" \" AIFF \"\n  TRACK 03 AUDIO\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fileType := cuesheet.File[0].FileType; fileType != "WAVE" || ValidateFileType(fileType) != nil {
+		t.Errorf("expected a quoted WAVE to read as a valid WAVE, got: %q", fileType)
+	}
+	if ValidateFileType(cuesheet.File[1].FileType) == nil || ValidateFileType(cuesheet.File[2].FileType) == nil {
+		t.Errorf("expected lowercase and padded types to be invalid before Canonicalize")
+	}
+
+	cuesheet.Canonicalize()
+	for i, expected := range []string{"WAVE", "MP3", "AIFF"} {
+		if fileType := cuesheet.File[i].FileType; fileType != expected || ValidateFileType(fileType) != nil {
+			t.Errorf("file %d: expected valid %s after Canonicalize, got: %q", i, expected, fileType)
+		}
+	}
+
+	var sb strings.Builder
+	if err := WriteFile(&sb, cuesheet); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"FILE a.wav WAVE\n", "FILE b.mp3 MP3\n", "FILE c.aiff AIFF\n"} {
+		if !strings.Contains(sb.String(), line) {
+			t.Errorf("expected %q in output, got:\n%s", line, sb.String())
+		}
+	}
+}
+
+func TestFormatTimecode(t *testing.T) {
+	tests := []struct {
+		frame    Frame
+		expected string
+	}{
+		{0, "00:00:00.000"},
+		{1, "00:00:00.013"},
+		{2, "00:00:00.027"},
+		{74, "00:00:00.987"},
+		{75, "00:00:01.000"},
+		{DurationToFrame(4*time.Minute) + 37, "00:04:00.493"},
+		{DurationToFrame(time.Hour) - 1, "00:59:59.987"},
+		{DurationToFrame(time.Hour), "01:00:00.000"},
+		{DurationToFrame(79*time.Minute+12*time.Second) + 50, "01:19:12.667"},
+		{DurationToFrame(125 * time.Hour), "125:00:00.000"},
+	}
+	for _, tt := range tests {
+		if got := tt.frame.FormatTimecode(); got != tt.expected {
+			t.Errorf("Frame(%d).FormatTimecode() = %s, want %s", tt.frame, got, tt.expected)
+		}
+	}
+
+	if got := FrameScale(30).FormatTimecode(45); got != "00:00:01.500" {
+		t.Errorf("expected 1.5s at 30 fps, got: %s", got)
+	}
+}
+
+func TestValidateCatalogChecksum(t *testing.T) {
+	tests := []struct {
+		code     string
+		catalog  error
+		checksum error
+		upcEan   error
+	}{
+		{"4006381333931", nil, nil, nil},                            // EAN-13 from the GS1 examples
+		{"5099902894423", nil, nil, nil},                            // EAN-13 printed on a CD
+		{"4006381333932", nil, ErrCheckDigit, ErrCheckDigit},        // wrong check digit
+		{"0000000000000", nil, nil, nil},                            // placeholder written by rippers
+		{"036000291452", strconv.ErrSyntax, strconv.ErrSyntax, nil}, // UPC-A
+		{"036000291453", strconv.ErrSyntax, strconv.ErrSyntax, ErrCheckDigit},
+		{"400638133393X", strconv.ErrSyntax, strconv.ErrSyntax, strconv.ErrSyntax},
+	}
+	for _, tt := range tests {
+		if err := ValidateCatalog(tt.code); !errors.Is(err, tt.catalog) {
+			t.Errorf("ValidateCatalog(%s) = %v, want %v", tt.code, err, tt.catalog)
+		}
+		if err := ValidateCatalogChecksum(tt.code); !errors.Is(err, tt.checksum) {
+			t.Errorf("ValidateCatalogChecksum(%s) = %v, want %v", tt.code, err, tt.checksum)
+		}
+		if err := ValidateUPCEAN(tt.code); !errors.Is(err, tt.upcEan) {
+			t.Errorf("ValidateUPCEAN(%s) = %v, want %v", tt.code, err, tt.upcEan)
+		}
+	}
+}
+
+func TestValidateDetailed(t *testing.T) {
+	input := `CATALOG 1234567890123
+FILE "a.wav" WAVE
+  TRACK 01 AUDIO
+    INDEX 01 00:00:00
+  TRACK 03 AUDIO
+    INDEX 00 03:00:00
+    INDEX 01 03:02:00
+  TRACK 04 AUDIO
+    INDEX 00 05:00:00
+`
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+
+	result := cuesheet.ValidateDetailed()
+	if result.Valid() || len(result.Errors) != 1 {
+		t.Fatalf("expected 1 error, got: %v", result.Errors)
+	}
+	if issue := result.Errors[0]; issue.Code != IssueMissingIndex01 || issue.TrackNumber != 4 || issue.FileIndex != 0 {
+		t.Errorf("expected missing INDEX 01 on track 04, got: %+v", issue)
+	}
+
+	var codes []IssueCode
+	for _, issue := range result.Warnings {
+		codes = append(codes, issue.Code)
+	}
+	expected := []IssueCode{IssueCatalogChecksum, IssueIndex00WithoutPregap, IssueIndex00WithoutPregap, IssueTrackNumberGap}
+	if !reflect.DeepEqual(codes, expected) {
+		t.Errorf("expected warnings %v, got: %v", expected, codes)
+	}
+
+	errs := cuesheet.Validate()
+	if len(errs) != 1 || !errors.Is(errs[0], strconv.ErrSyntax) {
+		t.Errorf("expected Validate to return only the INDEX 01 error, got: %v", errs)
+	}
+}
+
+func TestValidateTrackSequence(t *testing.T) {
+	tests := []struct {
+		name     string
+		numbers  [][]uint // track numbers per file
+		expected []error  // expected TrackErrors, in order
+	}{
+		{"Sequential", [][]uint{{1, 2}, {3}}, nil},
+		{"Duplicate", [][]uint{{1, 2, 2, 3}}, []error{&TrackError{2, ErrDuplicateTrack}}},
+		{"Gap", [][]uint{{1, 2}, {5}}, []error{&TrackError{5, ErrTrackNumberGap}}},
+		{"NotFromOne", [][]uint{{2, 3}}, []error{&TrackError{2, ErrTrackNumberGap}}},
+		{"OutOfOrder", [][]uint{{1, 3, 2, 4}}, []error{&TrackError{3, ErrTrackNumberGap}, &TrackError{2, ErrTrackOutOfOrder}}},
+		{"RestartPerFile", [][]uint{{1, 2}, {1, 2}}, []error{&TrackError{1, ErrDuplicateTrack}, &TrackError{2, ErrDuplicateTrack}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cuesheet Cuesheet
+			for i, numbers := range tt.numbers {
+				file := File{FileName: strconv.Itoa(i) + ".wav", FileType: "WAVE"}
+				for _, n := range numbers {
+					file.Tracks = append(file.Tracks, Track{TrackNumber: n, TrackDataType: "AUDIO", Index: []TrackIndex{{Number: 1}}})
+				}
+				cuesheet.File = append(cuesheet.File, file)
+			}
+
+			if errs := cuesheet.ValidateTrackSequence(); !reflect.DeepEqual(errs, tt.expected) {
+				t.Errorf("expected %v, got: %v", tt.expected, errs)
+			}
+
+			result := cuesheet.ValidateDetailed()
+			if len(result.Errors) != 0 || len(result.Warnings) != len(tt.expected) {
+				t.Errorf("expected %d warnings and no errors, got: %+v", len(tt.expected), result)
+			}
+		})
+	}
+}
+
+func TestValidateIndexOrder(t *testing.T) {
+	input := `FILE "a.wav" WAVE
+  TRACK 01 AUDIO
+    INDEX 01 00:00:00
+    INDEX 02 02:00:00
+  TRACK 02 AUDIO
+    INDEX 01 01:30:00
+  TRACK 03 AUDIO
+    INDEX 00 06:00:00
+    INDEX 01 05:58:00
+FILE "b.wav" WAVE
+  TRACK 04 AUDIO
+    INDEX 01 00:00:00
+`
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+
+	expected := []error{
+		&IndexOrderError{2, 1, 6750, 9000},
+		&IndexOrderError{3, 1, 26850, 27000},
+	}
+	if errs := cuesheet.ValidateIndexOrder(); !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %v, got: %v", expected, errs)
+	}
+
+	errs := cuesheet.Validate()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors from Validate, got: %v", errs)
+	}
+	var orderErr *IndexOrderError
+	if !errors.As(errs[0], &orderErr) || orderErr.TrackNumber != 2 {
+		t.Errorf("expected IndexOrderError for track 02, got: %v", errs[0])
+	}
+	if msg := errs[1].Error(); msg != "track 03 INDEX 01 at 05:58:00 is before the preceding index at 06:00:00" {
+		t.Errorf("unexpected message: %s", msg)
+	}
+
+	track, _ := cuesheet.GetTrack(2)
+	track.Index[0].Frame = 9000
+	track, _ = cuesheet.GetTrack(3)
+	track.Index[0].Frame = 26700
+	if errs := cuesheet.ValidateIndexOrder(); len(errs) != 0 {
+		t.Errorf("expected no errors after fixing the indexes, got: %v", errs)
+	}
+}
+
+func TestNormalizeISRC(t *testing.T) {
+	valid := []string{
+		"USRC17607839",
+		"US-RC1-76-07839",
+		"US RC1 76 07839",
+		"usrc17607839",
+		"us-rc1-76-07839 ",
+		"gb-a1b-24-00001",
+	}
+	for _, s := range valid {
+		isrc, err := NormalizeISRC(s)
+		if err != nil {
+			t.Errorf("NormalizeISRC(%q) error: %v", s, err)
+			continue
+		}
+		if ValidateISRC(isrc) != nil || len(isrc) != 12 || isrc != strings.ToUpper(isrc) {
+			t.Errorf("NormalizeISRC(%q) = %q, expected the canonical form", s, isrc)
+		}
+	}
+	if isrc, _ := NormalizeISRC("us-rc1-76-07839"); isrc != "USRC17607839" {
+		t.Errorf("expected USRC17607839, got: %q", isrc)
+	}
+
+	invalid := []string{"", "US-RC1-76-0783", "US_RC1_76_07839", "12-RC1-76-07839", "US-RC1-7A-07839", "US-RC1-76-078390"}
+	for _, s := range invalid {
+		if isrc, err := NormalizeISRC(s); err == nil {
+			t.Errorf("NormalizeISRC(%q) = %q, expected an error", s, isrc)
+		}
+	}
+
+	if err := ValidateISRC("usrc17607839"); err == nil {
+		t.Error("expected ValidateISRC to reject a lowercase ISRC")
+	}
+
+	input := "FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    ISRC us-rc1-76-07839\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    ISRC bogus\n    INDEX 01 04:00:00\n"
+	cuesheet, err := ReadFileWithOptions(strings.NewReader(input), ReadOptions{NormalizeISRC: true})
+	if err != nil {
+		t.Fatalf("ReadFileWithOptions error: %v", err)
+	}
+	if isrc := cuesheet.File[0].Tracks[0].Isrc; isrc != "USRC17607839" {
+		t.Errorf("expected normalized ISRC, got: %q", isrc)
+	}
+	if isrc := cuesheet.File[0].Tracks[1].Isrc; isrc != "bogus" {
+		t.Errorf("expected an invalid ISRC to be kept as written, got: %q", isrc)
+	}
+
+	cuesheet, err = ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	if isrc := cuesheet.File[0].Tracks[0].Isrc; isrc != "us-rc1-76-07839" {
+		t.Errorf("expected ISRC kept as written by default, got: %q", isrc)
+	}
+}
+
+func TestUnknownCommandsRoundTrip(t *testing.T) {
+	input := `REM GENRE "Ambient"
+TITLE "Album"
+RECORDED_BY "Studio X" 2019
+FILE "a.wav" WAVE
+  TRACK 01 AUDIO
+    INDEX 01 00:00:00
+X-VENDOR-CHECKSUM 0xDEADBEEF
+FILE "b.wav" WAVE
+  TRACK 02 AUDIO
+    INDEX 01 00:00:00
+`
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+
+	expected := []RawLine{
+		{`RECORDED_BY "Studio X" 2019`, 0},
+		{"X-VENDOR-CHECKSUM 0xDEADBEEF", 1},
+	}
+	if !reflect.DeepEqual(cuesheet.Unknown, expected) {
+		t.Errorf("expected unknown lines %v, got: %v", expected, cuesheet.Unknown)
+	}
+	if cuesheet.TrackCount() != 2 {
+		t.Errorf("expected 2 tracks, got: %d", cuesheet.TrackCount())
+	}
+
+	var sb strings.Builder
+	if err := WriteFile(&sb, cuesheet); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	output := `REM GENRE "Ambient"
+TITLE Album
+RECORDED_BY "Studio X" 2019
+FILE a.wav WAVE
+  TRACK 01 AUDIO
+    INDEX 01 00:00:00
+X-VENDOR-CHECKSUM 0xDEADBEEF
+FILE b.wav WAVE
+  TRACK 02 AUDIO
+    INDEX 01 00:00:00
+`
+	if sb.String() != output {
+		t.Errorf("expected:\n%s\ngot:\n%s", output, sb.String())
+	}
+
+	reread, err := ReadFile(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("ReadFile of written output error: %v", err)
+	}
+	if !reflect.DeepEqual(cuesheet, reread) {
+		t.Errorf("round-trip mismatch:\n%+v\n%+v", cuesheet, reread)
+	}
+}
+
+func TestReadFileStrict(t *testing.T) {
+	for _, name := range []string{"testdata/sample_1.cue", "testdata/sample_2.cue"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadFileStrict(strings.NewReader(string(data))); err != nil {
+			t.Errorf("%s: unexpected strict error: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		line  int
+		err   error
+	}{
+		{
+			"UnknownTopLevel",
+			"TITLE \"Album\"\nRECORDED_BY someone\nFILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n",
+			2, ErrUnknownCommand,
+		},
+		{
+			"UnknownInTrack",
+			"FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    LYRICIST \"Someone\"\n    INDEX 01 00:00:00\n",
+			3, ErrUnknownCommand,
+		},
+		{
+			"MissingIndex01",
+			"FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 00 03:00:00\n",
+			4, ErrMissingStartIndex,
+		},
+		{
+			"IndexOutOfRange",
+			"FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n    INDEX 100 01:00:00\n",
+			4, strconv.ErrRange,
+		},
+		{
+			"InvalidFileType",
+			"FILE \"a.wav\" WAV\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n",
+			1, ErrInvalidFileType,
+		},
+		{
+			"TrackWithoutFile",
+			"TITLE \"Album\"\nTRACK 01 AUDIO\n  INDEX 01 00:00:00\nFILE \"a.wav\" WAVE\n  TRACK 02 AUDIO\n    INDEX 01 00:00:00\n",
+			2, ErrTrackWithoutFile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadFile(strings.NewReader(tt.input)); err != nil {
+				t.Fatalf("expected lenient parsing to succeed, got: %v", err)
+			}
+
+			_, err := ReadFileStrict(strings.NewReader(tt.input))
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected ParseError, got: %v", err)
+			}
+			if parseErr.Line != tt.line || !errors.Is(err, tt.err) {
+				t.Errorf("expected %v at line %d, got: %v", tt.err, tt.line, err)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	input := "REM GENRE \"Jazz\"\r\nTITLE \"Notepad Album\"\r\nFILE \"a.wav\" WAVE\r\n  TRACK 01 AUDIO\r\n    INDEX 01 00:00:00\r\n"
+	data := append([]byte{0xef, 0xbb, 0xbf}, input...)
+
+	cuesheet, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if !reflect.DeepEqual(cuesheet.Rem, []string{"GENRE \"Jazz\""}) {
+		t.Errorf("expected the first REM after the BOM, got: %q", cuesheet.Rem)
+	}
+	if cuesheet.Title != "Notepad Album" || cuesheet.TrackCount() != 1 {
+		t.Errorf("unexpected cuesheet: %+v", cuesheet)
+	}
+
+	fromString, err := ParseString(string(data))
+	if err != nil {
+		t.Fatalf("ParseString error: %v", err)
+	}
+	if !reflect.DeepEqual(cuesheet, fromString) {
+		t.Errorf("expected Parse and ParseString to agree:\n%+v\n%+v", cuesheet, fromString)
+	}
+}
+
+func TestReadStringEscapedQuote(t *testing.T) {
+	line := `"a\"b.wav" WAVE`
+	if name := ReadString(&line); name != `a"b.wav` {
+		t.Errorf("expected name 'a\"b.wav', got: %q", name)
+	}
+	if fileType := ReadString(&line); fileType != "WAVE" {
+		t.Errorf("expected file type 'WAVE', got: %q", fileType)
+	}
+
+	cuesheet, err := ParseString("FILE \"a\\\"b.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n")
+	if err != nil {
+		t.Fatalf("ParseString error: %v", err)
+	}
+	if f := cuesheet.File[0]; f.FileName != `a"b.wav` || f.FileType != "WAVE" {
+		t.Errorf("expected FILE 'a\"b.wav' WAVE, got: %q %q", f.FileName, f.FileType)
+	}
+}
+
+func TestJoinQuotedLines(t *testing.T) {
+	input := "TITLE \"A Very Long Album Title That\r\n  Was Split Across Lines\"\r\nFILE \"a.wav\" WAVE\r\n  TRACK 01 AUDIO\r\n    TITLE \"Say \\\"Hi\\\"\"\r\n    INDEX 01 00:00:00\r\n  TRACK 02 AUDIO\r\n    INDEX 01 bad\r\n"
+
+	_, err := ReadFileWithOptions(strings.NewReader(input), ReadOptions{JoinQuotedLines: true})
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 8 {
+		t.Fatalf("expected a parse error on physical line 8, got: %v", err)
+	}
+
+	input = strings.Replace(input, "bad", "04:00:00", 1)
+	cuesheet, err := ReadFileWithOptions(strings.NewReader(input), ReadOptions{JoinQuotedLines: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cuesheet.Title != "A Very Long Album Title That Was Split Across Lines" {
+		t.Errorf("expected the split TITLE to be joined with a space, got: %q", cuesheet.Title)
+	}
+	if title := cuesheet.File[0].Tracks[0].Title; title != `Say "Hi"` {
+		t.Errorf("expected escaped quotes to close the value, got: %q", title)
+	}
+	if cuesheet.TrackCount() != 2 {
+		t.Errorf("expected 2 tracks, got: %d", cuesheet.TrackCount())
+	}
+
+	cuesheet, err = ReadFileWithOptions(strings.NewReader(input), ReadOptions{JoinQuotedLines: true, QuotedLineSeparator: "\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cuesheet.Title != "A Very Long Album Title That\nWas Split Across Lines" {
+		t.Errorf("expected the split TITLE to be joined with a newline, got: %q", cuesheet.Title)
+	}
+
+	plain, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Title != "A Very Long Album Title That" {
+		t.Errorf("expected the value to end with the line by default, got: %q", plain.Title)
+	}
+}
+
+func TestJoinQuotedLinesUnterminated(t *testing.T) {
+	input := "TITLE \"Stray\r\nFILE \"a.wav\" WAVE\r\n  TRACK 01 AUDIO\r\n    TITLE \"Open\r\n    INDEX 01 00:00:00\r\n  TRACK 02 AUDIO\r\n    PERFORMER \"Last\r\n"
+
+	var warnings []string
+	opts := ReadOptions{JoinQuotedLines: true, Warn: func(msg string) { warnings = append(warnings, msg) }}
+	cuesheet, err := ReadFileWithOptions(strings.NewReader(input), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cuesheet.Title != "Stray" {
+		t.Errorf("expected the open TITLE to end before FILE, got: %q", cuesheet.Title)
+	}
+	if cuesheet.TrackCount() != 2 {
+		t.Fatalf("expected 2 tracks, got: %d", cuesheet.TrackCount())
+	}
+	if title := cuesheet.File[0].Tracks[0].Title; title != "Open" {
+		t.Errorf("expected the open track TITLE to end before INDEX, got: %q", title)
+	}
+	if len(cuesheet.File[0].Tracks[0].Index) != 1 {
+		t.Errorf("expected the INDEX after the open TITLE to be parsed")
+	}
+	if len(warnings) != 3 {
+		t.Errorf("expected 3 warnings, got: %q", warnings)
+	}
+
+	input = strings.Replace(input, "00:00:00", "bad", 1)
+	_, err = ReadFileWithOptions(strings.NewReader(input), opts)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 5 {
+		t.Fatalf("expected a parse error on physical line 5, got: %v", err)
+	}
+}
+
+func TestValidateCdText(t *testing.T) {
+	input := "TITLE \"" + strings.Repeat("x", 90) + "\"\nPERFORMER \"Beyoncé\"\nFILE \"a.wav\" WAVE\n" +
+		"  TRACK 01 AUDIO\n    TITLE \"Party 🎉\"\n    PERFORMER \"坂本龍一\"\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ParseString(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if issues := cuesheet.ValidateCdText(0); len(issues) != 1 || issues[0].Code != IssueCdTextCharset {
+		t.Fatalf("expected only the emoji at the default limit, got: %v", issues)
+	}
+
+	issues := cuesheet.ValidateCdText(80)
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got: %v", issues)
+	}
+	if issue := issues[0]; issue.Code != IssueCdTextLength || issue.FileIndex != -1 || issue.TrackNumber != 0 {
+		t.Errorf("expected a length issue for the album title, got: %+v", issue)
+	}
+	var lengthErr *CdTextLengthError
+	if !errors.As(issues[0].Err, &lengthErr) || lengthErr.Length != 90 || lengthErr.Limit != 80 {
+		t.Errorf("expected CdTextLengthError of 90 > 80, got: %v", issues[0].Err)
+	}
+	if issue := issues[1]; issue.Code != IssueCdTextCharset || issue.FileIndex != 0 || issue.TrackNumber != 1 {
+		t.Errorf("expected a charset issue for track 01, got: %+v", issue)
+	}
+	if msg := issues[1].Message; msg != "track 01 TITLE: character '🎉' cannot be encoded in CD-TEXT" {
+		t.Errorf("unexpected message: %s", msg)
+	}
+
+	result := cuesheet.ValidateDetailed()
+	if !result.Valid() || len(result.Warnings) != 1 || result.Warnings[0].Code != IssueCdTextCharset {
+		t.Errorf("expected the emoji as the only detailed warning, got: %+v", result)
+	}
+}
+
+func TestAllTracks(t *testing.T) {
+	file, err := os.Open("testdata/sample_2.cue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	cuesheet, err := ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	tracks := cuesheet.AllTracks()
+	if len(tracks) != 10 {
+		t.Fatalf("expected 10 tracks, got: %d", len(tracks))
+	}
+	for i, track := range tracks {
+		if track.TrackNumber != uint(i+1) {
+			t.Errorf("position %d: expected track %d, got: %d", i, i+1, track.TrackNumber)
+		}
+		if track != &cuesheet.File[i].Tracks[0] {
+			t.Errorf("position %d: expected a pointer into the cuesheet", i)
+		}
+		if at := cuesheet.TrackAt(i); at != track {
+			t.Errorf("TrackAt(%d): expected track %d, got: %v", i, track.TrackNumber, at)
+		}
+
+		next := cuesheet.NextTrack(track)
+		if i+1 < len(tracks) && next != tracks[i+1] {
+			t.Errorf("NextTrack of track %d: expected track %d, got: %v", track.TrackNumber, i+2, next)
+		}
+		if i+1 == len(tracks) && next != nil {
+			t.Errorf("expected no track after the last one, got: %v", next)
+		}
+	}
+
+	if cuesheet.TrackAt(-1) != nil || cuesheet.TrackAt(10) != nil {
+		t.Error("expected nil for positions out of range")
+	}
+	if cuesheet.NextTrack(&Track{TrackNumber: 1}) != nil {
+		t.Error("expected nil for a track not in the cuesheet")
+	}
+}
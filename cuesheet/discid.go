@@ -0,0 +1,100 @@
+package cuesheet
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNoTracks is returned when a disc ID is requested for a sheet without tracks
+	ErrNoTracks = errors.New("cuesheet has no tracks")
+	// ErrLeadoutBeforeTrack is returned when the lead-out is not after the last track
+	ErrLeadoutBeforeTrack = errors.New("lead-out is not after the last track")
+)
+
+// tocOffsets returns the absolute disc address of every track's INDEX 01,
+// i.e. its frame position plus the 150-frame lead-in
+// The positions must be disc-relative, so sheets with more than one FILE
+// should be flattened first
+func (c *Cuesheet) tocOffsets(leadoutFrame Frame) ([]Frame, error) {
+	if len(c.File) > 1 {
+		return nil, ErrNotSingleFile
+	}
+	if c.TrackCount() == 0 {
+		return nil, ErrNoTracks
+	}
+	tracks := c.File[0].Tracks
+	offsets := make([]Frame, len(tracks))
+	for i := range tracks {
+		start, err := tracks[i].StartPosition()
+		if err != nil {
+			return nil, &TrackError{tracks[i].TrackNumber, err}
+		}
+		offsets[i] = start + LeadInFrames
+	}
+	if leadoutFrame+LeadInFrames <= offsets[len(offsets)-1] {
+		return nil, ErrLeadoutBeforeTrack
+	}
+	return offsets, nil
+}
+
+// CDDBDiscID computes the FreeDB/CDDB disc ID as 8 lowercase hex digits
+// The sheet does not record where the disc ends, so leadoutFrame must give
+// the program-area length in frames (the LBA of the lead-out, as for
+// TotalSectors), e.g. from the length of the ripped audio. Track offsets are
+// the INDEX 01 positions plus the 2-second lead-in; a sheet with several
+// FILE entries returns ErrNotSingleFile and should be flattened first
+func (c *Cuesheet) CDDBDiscID(leadoutFrame Frame) (string, error) {
+	offsets, err := c.tocOffsets(leadoutFrame)
+	if err != nil {
+		return "", err
+	}
+
+	sum := 0
+	for _, offset := range offsets {
+		for n := int(offset / FramesPerSecond); n > 0; n /= 10 {
+			sum += n % 10
+		}
+	}
+	length := int((leadoutFrame+LeadInFrames)/FramesPerSecond) - int(offsets[0]/FramesPerSecond)
+	id := uint32(sum%0xff)<<24 | uint32(length)<<8 | uint32(len(offsets))
+	return fmt.Sprintf("%08x", id), nil
+}
+
+// musicBrainzEncoding is base64 with the URL-safe substitutes MusicBrainz uses
+var musicBrainzEncoding = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789._").WithPadding('-')
+
+// MusicBrainzDiscID computes the 28-character MusicBrainz disc ID
+// MusicBrainz hashes the absolute CD TOC, while cue INDEX positions are
+// relative to the start of the audio; the TOC is rebuilt by adding the
+// standard 150-frame (2 second) lead-in to every INDEX 01 and to
+// leadoutFrame, the program-area length in frames as for CDDBDiscID. Track
+// numbers must be 1-99 and the sheet must have a single FILE
+func (c *Cuesheet) MusicBrainzDiscID(leadoutFrame Frame) (string, error) {
+	offsets, err := c.tocOffsets(leadoutFrame)
+	if err != nil {
+		return "", err
+	}
+	tracks := c.File[0].Tracks
+
+	var toc [100]Frame
+	toc[0] = leadoutFrame + LeadInFrames
+	for i, track := range tracks {
+		if track.TrackNumber < 1 || track.TrackNumber > 99 {
+			return "", &TrackError{track.TrackNumber, strconv.ErrRange}
+		}
+		toc[track.TrackNumber] = offsets[i]
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%02X%02X", tracks[0].TrackNumber, tracks[len(tracks)-1].TrackNumber)
+	for _, offset := range toc {
+		fmt.Fprintf(&sb, "%08X", offset)
+	}
+	sum := sha1.Sum([]byte(sb.String()))
+	return musicBrainzEncoding.EncodeToString(sum[:]), nil
+}
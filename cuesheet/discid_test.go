@@ -0,0 +1,71 @@
+package cuesheet
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Published disc TOCs: absolute sector offsets of each track, lead-out last
+var (
+	// Example disc of the libdiscid tests, FreeDB ID 830abf0a
+	cddbTOC = []Frame{150, 18901, 39738, 59557, 79152, 100126, 124833, 147278, 166336, 182560, 206535}
+	// Example disc of the python-discid documentation
+	musicBrainzTOC = []Frame{150, 15363, 32314, 46592, 63414, 80489, 95462}
+)
+
+// readDiscTOC builds a single-file cuesheet from a TOC and returns it with
+// the lead-out as a program-area frame count
+func readDiscTOC(t *testing.T, toc []Frame) (*Cuesheet, Frame) {
+	t.Helper()
+	var sb strings.Builder
+	sb.WriteString("FILE \"disc.wav\" WAVE\n")
+	for i, offset := range toc[:len(toc)-1] {
+		sb.WriteString("  TRACK " + FormatTrackNumber(uint(i+1)) + " AUDIO\n")
+		sb.WriteString("    INDEX 01 " + FormatFrame(offset-LeadInFrames) + "\n")
+	}
+	cuesheet, err := ReadFile(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cuesheet, toc[len(toc)-1] - LeadInFrames
+}
+
+func TestCDDBDiscID(t *testing.T) {
+	cuesheet, leadout := readDiscTOC(t, cddbTOC)
+	id, err := cuesheet.CDDBDiscID(leadout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "830abf0a" {
+		t.Errorf("expected disc ID 830abf0a, got: %s", id)
+	}
+
+	if _, err := cuesheet.CDDBDiscID(100); !errors.Is(err, ErrLeadoutBeforeTrack) {
+		t.Errorf("expected ErrLeadoutBeforeTrack, got: %v", err)
+	}
+	if _, err := (&Cuesheet{}).CDDBDiscID(leadout); !errors.Is(err, ErrNoTracks) {
+		t.Errorf("expected ErrNoTracks, got: %v", err)
+	}
+	cuesheet.File = append(cuesheet.File, cuesheet.File[0])
+	if _, err := cuesheet.CDDBDiscID(leadout); !errors.Is(err, ErrNotSingleFile) {
+		t.Errorf("expected ErrNotSingleFile, got: %v", err)
+	}
+}
+
+func TestMusicBrainzDiscID(t *testing.T) {
+	cuesheet, leadout := readDiscTOC(t, musicBrainzTOC)
+	id, err := cuesheet.MusicBrainzDiscID(leadout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "49HHV7Eb8UKF3aQiNmu1GR8vKTY-" {
+		t.Errorf("expected disc ID 49HHV7Eb8UKF3aQiNmu1GR8vKTY-, got: %s", id)
+	}
+
+	cuesheet.File[0].Tracks[0].TrackNumber = 100
+	if _, err := cuesheet.MusicBrainzDiscID(leadout); !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("expected strconv.ErrRange for track 100, got: %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package cuesheet
+
+import (
+	"errors"
+	"sort"
+)
+
+var (
+	// ErrTrackNotFound is returned when no track has the requested number
+	ErrTrackNotFound = errors.New("track not found")
+	// ErrDuplicateTrack is returned when an inserted track reuses an existing number
+	ErrDuplicateTrack = errors.New("duplicate track number")
+	// ErrIndexOverlap is returned when a track's indexes are not strictly
+	// between those of its neighbors in the same file
+	ErrIndexOverlap = errors.New("track indexes overlap a neighboring track")
+)
+
+// EditOptions controls how track edits affect the rest of the cuesheet
+type EditOptions struct {
+	// Renumber shifts the numbers of the tracks after the edit so the
+	// numbering stays sequential. Off by default so existing numbers,
+	// which other metadata may refer to, never change by surprise
+	Renumber bool
+}
+
+// findTrack returns the file and track positions of the given track number
+func (c *Cuesheet) findTrack(number uint) (int, int, bool) {
+	for i := range c.File {
+		for j := range c.File[i].Tracks {
+			if c.File[i].Tracks[j].TrackNumber == number {
+				return i, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// shiftTrackNumbers adds delta to the number of every track after the given position
+func (c *Cuesheet) shiftTrackNumbers(file, track int, delta int) {
+	for i := file; i < len(c.File); i++ {
+		start := 0
+		if i == file {
+			start = track + 1
+		}
+		for j := start; j < len(c.File[i].Tracks); j++ {
+			c.File[i].Tracks[j].TrackNumber = uint(int(c.File[i].Tracks[j].TrackNumber) + delta)
+		}
+	}
+}
+
+// RemoveTrack removes the track with the given number without renumbering
+// the tracks after it. A FILE left without tracks is removed as well
+func (c *Cuesheet) RemoveTrack(trackNumber uint) error {
+	return c.RemoveTrackWithOptions(trackNumber, EditOptions{})
+}
+
+// RemoveTrackWithOptions removes the track with the given number using the given options
+func (c *Cuesheet) RemoveTrackWithOptions(trackNumber uint, opts EditOptions) error {
+	i, j, ok := c.findTrack(trackNumber)
+	if !ok {
+		return ErrTrackNotFound
+	}
+
+	if opts.Renumber {
+		c.shiftTrackNumbers(i, j, -1)
+	}
+
+	f := &c.File[i]
+	f.Tracks = append(f.Tracks[:j], f.Tracks[j+1:]...)
+	if len(f.Tracks) == 0 {
+		c.File = append(c.File[:i], c.File[i+1:]...)
+	}
+	return nil
+}
+
+// InsertTrack inserts t into the same FILE right after the track numbered
+// afterNumber, or at the start of the first FILE if afterNumber is 0
+// t keeps its own number, which must not already be in use
+func (c *Cuesheet) InsertTrack(afterNumber uint, t Track) error {
+	return c.InsertTrackWithOptions(afterNumber, t, EditOptions{})
+}
+
+// InsertTrackWithOptions inserts a track using the given options
+// With Renumber, t is numbered afterNumber+1 and the tracks after it shift up
+func (c *Cuesheet) InsertTrackWithOptions(afterNumber uint, t Track, opts EditOptions) error {
+	// i, j is the position the new track will occupy
+	i, j := 0, 0
+	if afterNumber > 0 {
+		var ok bool
+		if i, j, ok = c.findTrack(afterNumber); !ok {
+			return ErrTrackNotFound
+		}
+		j++
+	} else if len(c.File) == 0 {
+		return ErrTrackNotFound
+	}
+
+	if opts.Renumber {
+		t.TrackNumber = afterNumber + 1
+	} else if _, _, exists := c.findTrack(t.TrackNumber); exists {
+		return ErrDuplicateTrack
+	}
+
+	tracks := c.File[i].Tracks
+	if j > 0 && !indexesBefore(&tracks[j-1], &t) || j < len(tracks) && !indexesBefore(&t, &tracks[j]) {
+		return ErrIndexOverlap
+	}
+
+	if opts.Renumber {
+		c.shiftTrackNumbers(i, j-1, 1)
+	}
+	tracks = append(tracks, Track{})
+	copy(tracks[j+1:], tracks[j:])
+	tracks[j] = t
+	c.File[i].Tracks = tracks
+	return nil
+}
+
+// indexesBefore returns true if every index of a precedes every index of b
+func indexesBefore(a, b *Track) bool {
+	for _, ia := range a.Index {
+		for _, ib := range b.Index {
+			if ia.Frame >= ib.Frame {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+var (
+	// ErrSplitOutOfRange is returned when a split position is not inside the track
+	ErrSplitOutOfRange = errors.New("split position outside the track")
+	// ErrNotAdjacent is returned when merged tracks are not consecutive in one file
+	ErrNotAdjacent = errors.New("tracks are not adjacent in the same file")
+)
+
+// SplitTrack splits a track in two at the given frame, for rips where two
+// songs were captured as one track. The new track gets INDEX 01 at at, the
+// given title, and the data type, flags, performer and songwriter of the
+// original. Sub-indexes at or after at move to the new track along with the
+// POSTGAP, and the tracks after it are renumbered
+// at must be after the track's INDEX 01 and before the next track in the file
+func (c *Cuesheet) SplitTrack(trackNumber uint, at Frame, newTitle string) error {
+	i, j, ok := c.findTrack(trackNumber)
+	if !ok {
+		return ErrTrackNotFound
+	}
+	track := &c.File[i].Tracks[j]
+	start, err := track.StartPosition()
+	if err != nil || at <= start {
+		return ErrSplitOutOfRange
+	}
+	if next := j + 1; next < len(c.File[i].Tracks) {
+		if nextStart := c.File[i].Tracks[next].Index; len(nextStart) > 0 && at >= nextStart[0].Frame {
+			return ErrSplitOutOfRange
+		}
+	}
+
+	split := Track{
+		TrackDataType: track.TrackDataType,
+		Flags:         track.Flags,
+		Title:         newTitle,
+		Performer:     track.Performer,
+		SongWriter:    track.SongWriter,
+		Postgap:       track.Postgap,
+		Index:         []TrackIndex{{Number: 1, Frame: at}},
+	}
+	var kept []TrackIndex
+	for _, index := range track.Index {
+		if index.Frame < at {
+			kept = append(kept, index)
+		} else if index.Frame > at {
+			split.Index = append(split.Index, TrackIndex{Number: uint(len(split.Index) + 1), Frame: index.Frame})
+		}
+	}
+
+	// Shorten the original on a copy of the file's tracks so a failed insert
+	// leaves the cuesheet unchanged
+	original := c.File[i].Tracks
+	tracks := cloneSlice(original)
+	tracks[j].Index = kept
+	tracks[j].Postgap = 0
+	c.File[i].Tracks = tracks
+
+	if err := c.InsertTrackWithOptions(trackNumber, split, EditOptions{Renumber: true}); err != nil {
+		c.File[i].Tracks = original
+		return err
+	}
+	return nil
+}
+
+// MergeTracks merges second into first, for rips where one song was split
+// into two tracks. The merged track keeps the metadata of first; the INDEX 00
+// and INDEX 01 of second are dropped, its other indexes become sub-indexes of
+// first, and the tracks after it are renumbered
+// The two tracks must be consecutive in the same file
+func (c *Cuesheet) MergeTracks(first, second uint) error {
+	i, j, ok := c.findTrack(first)
+	if !ok {
+		return ErrTrackNotFound
+	}
+	k, l, ok := c.findTrack(second)
+	if !ok {
+		return ErrTrackNotFound
+	}
+	if k != i || l != j+1 {
+		return ErrNotAdjacent
+	}
+
+	merged := &c.File[i].Tracks[j]
+	next := &c.File[i].Tracks[l]
+	var last uint
+	for _, index := range merged.Index {
+		last = max(last, index.Number)
+	}
+	for _, index := range next.Index {
+		if index.Number > 1 {
+			last++
+			merged.Index = append(merged.Index, TrackIndex{Number: last, Frame: index.Frame})
+		}
+	}
+	merged.Postgap = next.Postgap
+
+	return c.RemoveTrackWithOptions(second, EditOptions{Renumber: true})
+}
+
+// Sort puts the sheet in playback order: the tracks of each FILE by number,
+// then the FILE entries by their first track, repairing sheets whose files
+// were shuffled by a bad merge. Positions are not checked or changed
+func (c *Cuesheet) Sort() {
+	for i := range c.File {
+		c.File[i].SortTracks()
+	}
+	c.SortFiles()
+}
+
+// SortTracks orders the tracks of the file by track number
+func (f *File) SortTracks() {
+	sort.SliceStable(f.Tracks, func(i, j int) bool {
+		return f.Tracks[i].TrackNumber < f.Tracks[j].TrackNumber
+	})
+}
+
+// SortFiles orders the FILE entries by the number of their first track so
+// WriteFile emits them in playback order; files without tracks go last
+func (c *Cuesheet) SortFiles() {
+	first := func(f *File) uint {
+		if len(f.Tracks) == 0 {
+			return ^uint(0)
+		}
+		return f.Tracks[0].TrackNumber
+	}
+	sort.SliceStable(c.File, func(i, j int) bool {
+		return first(&c.File[i]) < first(&c.File[j])
+	})
+}
@@ -0,0 +1,230 @@
+package cuesheet
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const editCue = "FILE \"a.wav\" WAVE\n" +
+	"  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n" +
+	"  TRACK 02 AUDIO\n    INDEX 01 03:00:00\n" +
+	"  TRACK 03 AUDIO\n    INDEX 01 06:00:00\n" +
+	"FILE \"b.wav\" WAVE\n" +
+	"  TRACK 04 AUDIO\n    INDEX 01 00:00:00\n"
+
+func readEditCue(t *testing.T) *Cuesheet {
+	t.Helper()
+	cuesheet, err := ReadFile(strings.NewReader(editCue))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cuesheet
+}
+
+// trackLayout lists each track as "file:number@start"
+func trackLayout(c *Cuesheet) []string {
+	var layout []string
+	for _, f := range c.File {
+		for _, track := range f.Tracks {
+			start, _ := track.StartPosition()
+			layout = append(layout, f.FileName+":"+FormatTrackNumber(track.TrackNumber)+"@"+FormatFrame(start))
+		}
+	}
+	return layout
+}
+
+func TestRemoveTrack(t *testing.T) {
+	t.Run("KeepNumbers", func(t *testing.T) {
+		cuesheet := readEditCue(t)
+		if err := cuesheet.RemoveTrack(2); err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{"a.wav:01@00:00:00", "a.wav:03@06:00:00", "b.wav:04@00:00:00"}
+		if layout := trackLayout(cuesheet); !reflect.DeepEqual(layout, expected) {
+			t.Errorf("expected %q, got %q", expected, layout)
+		}
+	})
+
+	t.Run("Renumber", func(t *testing.T) {
+		cuesheet := readEditCue(t)
+		if err := cuesheet.RemoveTrackWithOptions(2, EditOptions{Renumber: true}); err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{"a.wav:01@00:00:00", "a.wav:02@06:00:00", "b.wav:03@00:00:00"}
+		if layout := trackLayout(cuesheet); !reflect.DeepEqual(layout, expected) {
+			t.Errorf("expected %q, got %q", expected, layout)
+		}
+	})
+
+	t.Run("EmptyFileRemoved", func(t *testing.T) {
+		cuesheet := readEditCue(t)
+		if err := cuesheet.RemoveTrack(4); err != nil {
+			t.Fatal(err)
+		}
+		if len(cuesheet.File) != 1 {
+			t.Errorf("expected the emptied FILE to be removed, got %d files", len(cuesheet.File))
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		cuesheet := readEditCue(t)
+		if err := cuesheet.RemoveTrack(9); !errors.Is(err, ErrTrackNotFound) {
+			t.Errorf("expected ErrTrackNotFound, got: %v", err)
+		}
+	})
+}
+
+func TestInsertTrack(t *testing.T) {
+	newTrack := func(number uint, start Frame) Track {
+		return Track{TrackNumber: number, TrackDataType: "AUDIO", Index: []TrackIndex{{Number: 1, Frame: start}}}
+	}
+
+	t.Run("KeepNumbers", func(t *testing.T) {
+		cuesheet := readEditCue(t)
+		if err := cuesheet.InsertTrack(1, newTrack(10, DurationToFrame(90*time.Second))); err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{"a.wav:01@00:00:00", "a.wav:10@01:30:00", "a.wav:02@03:00:00", "a.wav:03@06:00:00", "b.wav:04@00:00:00"}
+		if layout := trackLayout(cuesheet); !reflect.DeepEqual(layout, expected) {
+			t.Errorf("expected %q, got %q", expected, layout)
+		}
+	})
+
+	t.Run("Renumber", func(t *testing.T) {
+		cuesheet := readEditCue(t)
+		if err := cuesheet.InsertTrackWithOptions(2, newTrack(0, DurationToFrame(270*time.Second)), EditOptions{Renumber: true}); err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{"a.wav:01@00:00:00", "a.wav:02@03:00:00", "a.wav:03@04:30:00", "a.wav:04@06:00:00", "b.wav:05@00:00:00"}
+		if layout := trackLayout(cuesheet); !reflect.DeepEqual(layout, expected) {
+			t.Errorf("expected %q, got %q", expected, layout)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		cuesheet := readEditCue(t)
+		if err := cuesheet.InsertTrack(1, newTrack(5, DurationToFrame(4*time.Minute))); !errors.Is(err, ErrIndexOverlap) {
+			t.Errorf("expected ErrIndexOverlap for a start after the next track, got: %v", err)
+		}
+		if err := cuesheet.InsertTrack(2, newTrack(3, DurationToFrame(4*time.Minute))); !errors.Is(err, ErrDuplicateTrack) {
+			t.Errorf("expected ErrDuplicateTrack, got: %v", err)
+		}
+		if err := cuesheet.InsertTrack(7, newTrack(8, 0)); !errors.Is(err, ErrTrackNotFound) {
+			t.Errorf("expected ErrTrackNotFound, got: %v", err)
+		}
+		if layout := trackLayout(cuesheet); len(layout) != 4 {
+			t.Errorf("expected failed inserts to leave the cuesheet unchanged, got %q", layout)
+		}
+	})
+}
+
+func TestSplitTrack(t *testing.T) {
+	cuesheet := readEditCue(t)
+	track, _ := cuesheet.GetTrack(2)
+	track.Index = append(track.Index, TrackIndex{Number: 2, Frame: DurationToFrame(5 * time.Minute)})
+
+	at := DurationToFrame(4*time.Minute + 15*time.Second)
+	if err := cuesheet.SplitTrack(2, at, "Second Song"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"a.wav:01@00:00:00", "a.wav:02@03:00:00", "a.wav:03@04:15:00", "a.wav:04@06:00:00", "b.wav:05@00:00:00"}
+	if layout := trackLayout(cuesheet); !reflect.DeepEqual(layout, expected) {
+		t.Errorf("expected %q, got %q", expected, layout)
+	}
+
+	original, _ := cuesheet.GetTrack(2)
+	split, _ := cuesheet.GetTrack(3)
+	if len(original.Index) != 1 {
+		t.Errorf("expected the original track to keep only INDEX 01, got: %+v", original.Index)
+	}
+	expectedIndex := []TrackIndex{{Number: 1, Frame: at}, {Number: 2, Frame: DurationToFrame(5 * time.Minute)}}
+	if split.Title != "Second Song" || split.TrackDataType != "AUDIO" || !reflect.DeepEqual(split.Index, expectedIndex) {
+		t.Errorf("unexpected split track: %+v", split)
+	}
+
+	for _, at := range []Frame{0, DurationToFrame(3 * time.Minute), DurationToFrame(6 * time.Minute)} {
+		if err := cuesheet.SplitTrack(2, at, "Bad"); !errors.Is(err, ErrSplitOutOfRange) {
+			t.Errorf("expected ErrSplitOutOfRange splitting at %s, got: %v", FormatFrame(at), err)
+		}
+	}
+}
+
+func TestSplitTrackFailureLeavesSheetUnchanged(t *testing.T) {
+	cuesheet := readEditCue(t)
+	track, _ := cuesheet.GetTrack(2)
+	// A corrupt sub-index past the start of track 03 makes the insert fail
+	track.Index = append(track.Index, TrackIndex{Number: 2, Frame: DurationToFrame(7 * time.Minute)})
+	track.Postgap = DurationToFrame(2 * time.Second)
+	before := cuesheet.clone()
+
+	if err := cuesheet.SplitTrack(2, DurationToFrame(4*time.Minute), "Bad"); !errors.Is(err, ErrIndexOverlap) {
+		t.Fatalf("expected ErrIndexOverlap, got: %v", err)
+	}
+	if !reflect.DeepEqual(cuesheet, before) {
+		t.Errorf("expected a failed split to leave the cuesheet unchanged:\n%+v\n%+v", before, cuesheet)
+	}
+}
+
+func TestMergeTracks(t *testing.T) {
+	cuesheet := readEditCue(t)
+	second, _ := cuesheet.GetTrack(3)
+	second.Index = []TrackIndex{{Number: 0, Frame: DurationToFrame(5 * time.Minute)}, {Number: 1, Frame: DurationToFrame(6 * time.Minute)}, {Number: 2, Frame: DurationToFrame(7 * time.Minute)}}
+
+	if err := cuesheet.MergeTracks(2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"a.wav:01@00:00:00", "a.wav:02@03:00:00", "b.wav:03@00:00:00"}
+	if layout := trackLayout(cuesheet); !reflect.DeepEqual(layout, expected) {
+		t.Errorf("expected %q, got %q", expected, layout)
+	}
+	merged, _ := cuesheet.GetTrack(2)
+	expectedIndex := []TrackIndex{{Number: 1, Frame: DurationToFrame(3 * time.Minute)}, {Number: 2, Frame: DurationToFrame(7 * time.Minute)}}
+	if !reflect.DeepEqual(merged.Index, expectedIndex) {
+		t.Errorf("expected indexes %+v, got %+v", expectedIndex, merged.Index)
+	}
+
+	if err := cuesheet.MergeTracks(2, 3); !errors.Is(err, ErrNotAdjacent) {
+		t.Errorf("expected ErrNotAdjacent across files, got: %v", err)
+	}
+	if err := cuesheet.MergeTracks(1, 1); !errors.Is(err, ErrNotAdjacent) {
+		t.Errorf("expected ErrNotAdjacent merging a track with itself, got: %v", err)
+	}
+	if err := cuesheet.MergeTracks(3, 4); !errors.Is(err, ErrTrackNotFound) {
+		t.Errorf("expected ErrTrackNotFound, got: %v", err)
+	}
+}
+
+func TestSort(t *testing.T) {
+	input := "FILE \"c.wav\" WAVE\n  TRACK 05 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"empty.wav\" WAVE\n" +
+		"FILE \"a.wav\" WAVE\n  TRACK 02 AUDIO\n    INDEX 01 03:00:00\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"b.wav\" WAVE\n  TRACK 03 AUDIO\n    INDEX 01 00:00:00\n  TRACK 04 AUDIO\n    INDEX 01 02:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cuesheet.SortFiles()
+	expected := []string{"a.wav:02@03:00:00", "a.wav:01@00:00:00", "b.wav:03@00:00:00", "b.wav:04@02:00:00", "c.wav:05@00:00:00"}
+	if layout := trackLayout(cuesheet); !reflect.DeepEqual(layout, expected) {
+		t.Errorf("expected %v after SortFiles, got %v", expected, layout)
+	}
+
+	cuesheet.Sort()
+	var sb strings.Builder
+	if err := WriteFile(&sb, cuesheet); err != nil {
+		t.Fatal(err)
+	}
+	output := "FILE a.wav WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 01 03:00:00\n" +
+		"FILE b.wav WAVE\n  TRACK 03 AUDIO\n    INDEX 01 00:00:00\n  TRACK 04 AUDIO\n    INDEX 01 02:00:00\n" +
+		"FILE c.wav WAVE\n  TRACK 05 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE empty.wav WAVE\n"
+	if sb.String() != output {
+		t.Errorf("expected:\n%s\ngot:\n%s", output, sb.String())
+	}
+}
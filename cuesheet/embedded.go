@@ -0,0 +1,239 @@
+package cuesheet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// ErrNoEmbeddedCue is returned when an audio file carries no embedded cuesheet
+var ErrNoEmbeddedCue = errors.New("no embedded cuesheet found")
+
+const (
+	apeFooterSize        = 32
+	id3v1Size            = 128
+	id3v2HeaderSize      = 10
+	id3v2FrameHeaderSize = 10
+)
+
+// ReadEmbeddedCue parses a cuesheet embedded in the tags of an audio file
+// The APEv2 "Cuesheet" item (Monkey's Audio, WavPack) is tried first,
+// then an ID3v2 TXXX frame described as "CUESHEET" (MP3)
+func ReadEmbeddedCue(r io.ReadSeeker) (*Cuesheet, error) {
+	text, err := ExtractAPEv2Cuesheet(r)
+	if errors.Is(err, ErrNoEmbeddedCue) {
+		text, err = ExtractID3v2Cuesheet(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ReadFile(strings.NewReader(text))
+}
+
+// ExtractAPEv2Cuesheet returns the text of the "Cuesheet" item of the APEv2 tag
+// at the end of r, which may be followed by an ID3v1 tag
+func ExtractAPEv2Cuesheet(r io.ReadSeeker) (string, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", err
+	}
+
+	// The APEv2 footer is the last 32 bytes, or sits just before an ID3v1 tag
+	footerOffsets := []int64{size - apeFooterSize, size - id3v1Size - apeFooterSize}
+	for _, offset := range footerOffsets {
+		if offset < 0 {
+			continue
+		}
+		footer := make([]byte, apeFooterSize)
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return "", err
+		}
+		if _, err := io.ReadFull(r, footer); err != nil {
+			return "", err
+		}
+		if string(footer[0:8]) != "APETAGEX" {
+			continue
+		}
+
+		// Tag size covers the items and the footer, but not the optional header
+		tagSize := int64(binary.LittleEndian.Uint32(footer[12:16]))
+		itemCount := binary.LittleEndian.Uint32(footer[16:20])
+		itemsSize := tagSize - apeFooterSize
+		if itemsSize < 0 || itemsSize > offset {
+			return "", ErrNoEmbeddedCue
+		}
+		items := make([]byte, itemsSize)
+		if _, err := r.Seek(offset-itemsSize, io.SeekStart); err != nil {
+			return "", err
+		}
+		if _, err := io.ReadFull(r, items); err != nil {
+			return "", err
+		}
+		return findAPEv2Item(items, itemCount, "cuesheet")
+	}
+
+	return "", ErrNoEmbeddedCue
+}
+
+// findAPEv2Item returns the value of the item whose key matches name case-insensitively
+func findAPEv2Item(items []byte, count uint32, name string) (string, error) {
+	for i := uint32(0); i < count && len(items) >= 8; i++ {
+		valueSize := int(binary.LittleEndian.Uint32(items[0:4]))
+		items = items[8:] // value size and item flags
+
+		keyEnd := bytes.IndexByte(items, 0)
+		if keyEnd < 0 {
+			break
+		}
+		key := string(items[:keyEnd])
+		items = items[keyEnd+1:]
+		if valueSize < 0 || valueSize > len(items) {
+			break
+		}
+		value := items[:valueSize]
+		items = items[valueSize:]
+
+		if strings.EqualFold(key, name) {
+			return string(value), nil
+		}
+	}
+	return "", ErrNoEmbeddedCue
+}
+
+// ExtractID3v2Cuesheet returns the text of the TXXX frame described as
+// "CUESHEET" in the ID3v2.3 or ID3v2.4 tag at the start of r
+func ExtractID3v2Cuesheet(r io.ReadSeeker) (string, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	header := make([]byte, id3v2HeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return "", ErrNoEmbeddedCue
+	}
+	major := header[3]
+	if string(header[0:3]) != "ID3" || (major != 3 && major != 4) {
+		return "", ErrNoEmbeddedCue
+	}
+
+	// The size field is untrusted; a tag cannot be larger than the file
+	tagSize := syncsafe(header[6:10])
+	if int64(tagSize) > size-id3v2HeaderSize {
+		return "", ErrNoEmbeddedCue
+	}
+	tag := make([]byte, tagSize)
+	if _, err := io.ReadFull(r, tag); err != nil {
+		return "", err
+	}
+
+	// Skip the extended header if present
+	if header[5]&0x40 != 0 && len(tag) >= 4 {
+		extSize := int(binary.BigEndian.Uint32(tag[0:4]))
+		if major == 3 {
+			extSize += 4 // v2.3 size excludes the size field itself
+		} else {
+			extSize = syncsafe(tag[0:4])
+		}
+		if extSize > len(tag) {
+			return "", ErrNoEmbeddedCue
+		}
+		tag = tag[extSize:]
+	}
+
+	for len(tag) >= id3v2FrameHeaderSize && tag[0] != 0 {
+		id := string(tag[0:4])
+		frameSize := int(binary.BigEndian.Uint32(tag[4:8]))
+		if major == 4 {
+			frameSize = syncsafe(tag[4:8])
+		}
+		tag = tag[id3v2FrameHeaderSize:]
+		if frameSize < 0 || frameSize > len(tag) {
+			break
+		}
+		frame := tag[:frameSize]
+		tag = tag[frameSize:]
+
+		if id != "TXXX" || len(frame) < 1 {
+			continue
+		}
+		description, value := splitID3Text(frame[0], frame[1:])
+		if strings.EqualFold(description, "CUESHEET") {
+			return value, nil
+		}
+	}
+
+	return "", ErrNoEmbeddedCue
+}
+
+// syncsafe decodes a 28-bit ID3v2 synchsafe integer
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
+
+// splitID3Text splits a TXXX payload into its description and value
+// using the frame's text encoding byte
+func splitID3Text(encoding byte, data []byte) (string, string) {
+	switch encoding {
+	case 1, 2: // UTF-16 with BOM, UTF-16BE
+		end := 0
+		for ; end+1 < len(data); end += 2 {
+			if data[end] == 0 && data[end+1] == 0 {
+				break
+			}
+		}
+		description := decodeUTF16(data[:end], encoding == 2)
+		value := []byte{}
+		if end+2 <= len(data) {
+			value = data[end+2:]
+		}
+		return description, decodeUTF16(value, encoding == 2)
+	default: // ISO-8859-1, UTF-8
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			return decodeID3Single(encoding, data), ""
+		}
+		value := bytes.TrimRight(data[end+1:], "\x00")
+		return decodeID3Single(encoding, data[:end]), decodeID3Single(encoding, value)
+	}
+}
+
+// decodeID3Single decodes ISO-8859-1 (encoding 0) or UTF-8 (encoding 3) text
+func decodeID3Single(encoding byte, data []byte) string {
+	if encoding == 3 {
+		return string(data)
+	}
+	runes := make([]rune, len(data))
+	for i, c := range data {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// decodeUTF16 decodes UTF-16 text, honoring a leading byte order mark
+func decodeUTF16(data []byte, bigEndian bool) string {
+	if len(data) >= 2 {
+		switch {
+		case data[0] == 0xff && data[1] == 0xfe:
+			bigEndian = false
+			data = data[2:]
+		case data[0] == 0xfe && data[1] == 0xff:
+			bigEndian = true
+			data = data[2:]
+		}
+	}
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, binary.BigEndian.Uint16(data[i:]))
+		} else {
+			units = append(units, binary.LittleEndian.Uint16(data[i:]))
+		}
+	}
+	return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+}
@@ -0,0 +1,150 @@
+package cuesheet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"unicode/utf16"
+)
+
+const embeddedCue = "TITLE \"Embedded Album\"\r\nFILE \"CDImage.ape\" WAVE\r\n  TRACK 01 AUDIO\r\n    TITLE \"Первый\"\r\n    INDEX 01 00:00:00\r\n  TRACK 02 AUDIO\r\n    INDEX 01 04:10:20\r\n"
+
+// apeTag builds an APEv2 tag (items followed by the footer)
+func apeTag(items map[string]string, keys ...string) []byte {
+	var body bytes.Buffer
+	for _, key := range keys {
+		binary.Write(&body, binary.LittleEndian, uint32(len(items[key])))
+		binary.Write(&body, binary.LittleEndian, uint32(0))
+		body.WriteString(key)
+		body.WriteByte(0)
+		body.WriteString(items[key])
+	}
+
+	var footer bytes.Buffer
+	footer.WriteString("APETAGEX")
+	binary.Write(&footer, binary.LittleEndian, uint32(2000))
+	binary.Write(&footer, binary.LittleEndian, uint32(body.Len()+apeFooterSize))
+	binary.Write(&footer, binary.LittleEndian, uint32(len(keys)))
+	binary.Write(&footer, binary.LittleEndian, uint32(0))
+	footer.Write(make([]byte, 8))
+
+	return append(body.Bytes(), footer.Bytes()...)
+}
+
+// id3v2Tag builds an ID3v2 tag holding a single TXXX frame
+func id3v2Tag(major byte, encoding byte, description, value string) []byte {
+	var payload bytes.Buffer
+	payload.WriteByte(encoding)
+	switch encoding {
+	case 1:
+		for _, s := range []string{description, value} {
+			payload.Write([]byte{0xff, 0xfe})
+			for _, u := range utf16.Encode([]rune(s)) {
+				binary.Write(&payload, binary.LittleEndian, u)
+			}
+			payload.Write([]byte{0, 0})
+		}
+	default:
+		payload.WriteString(description)
+		payload.WriteByte(0)
+		payload.WriteString(value)
+	}
+
+	toSyncsafe := func(n int) []byte {
+		return []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+	}
+
+	var frame bytes.Buffer
+	frame.WriteString("TXXX")
+	if major == 4 {
+		frame.Write(toSyncsafe(payload.Len()))
+	} else {
+		binary.Write(&frame, binary.BigEndian, uint32(payload.Len()))
+	}
+	frame.Write([]byte{0, 0})
+	frame.Write(payload.Bytes())
+
+	var tag bytes.Buffer
+	tag.WriteString("ID3")
+	tag.Write([]byte{major, 0, 0})
+	tag.Write(toSyncsafe(frame.Len()))
+	tag.Write(frame.Bytes())
+	return tag.Bytes()
+}
+
+func checkEmbeddedCue(t *testing.T, cuesheet *Cuesheet) {
+	t.Helper()
+	if cuesheet.Title != "Embedded Album" {
+		t.Errorf("expected title 'Embedded Album', got: '%s'", cuesheet.Title)
+	}
+	if cuesheet.TrackCount() != 2 {
+		t.Fatalf("expected 2 tracks, got: %d", cuesheet.TrackCount())
+	}
+	if title := cuesheet.File[0].Tracks[0].Title; title != "Первый" {
+		t.Errorf("expected track title 'Первый', got: '%s'", title)
+	}
+}
+
+func TestReadEmbeddedCue(t *testing.T) {
+	audio := bytes.Repeat([]byte{0x55}, 1024)
+	items := map[string]string{"Artist": "Someone", "CUESHEET": embeddedCue}
+
+	t.Run("APEv2", func(t *testing.T) {
+		data := append(append([]byte{}, audio...), apeTag(items, "Artist", "CUESHEET")...)
+		cuesheet, err := ReadEmbeddedCue(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("ReadEmbeddedCue error: %v", err)
+		}
+		checkEmbeddedCue(t, cuesheet)
+	})
+
+	t.Run("APEv2BeforeID3v1", func(t *testing.T) {
+		id3v1 := append([]byte("TAG"), make([]byte, id3v1Size-3)...)
+		data := append(append(append([]byte{}, audio...), apeTag(items, "CUESHEET")...), id3v1...)
+		text, err := ExtractAPEv2Cuesheet(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("ExtractAPEv2Cuesheet error: %v", err)
+		}
+		if text != embeddedCue {
+			t.Errorf("unexpected cuesheet text: %q", text)
+		}
+	})
+
+	t.Run("ID3v23Latin1", func(t *testing.T) {
+		latin1 := "TITLE \"Embedded Album\"\nFILE \"a.mp3\" MP3\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n"
+		data := append(id3v2Tag(3, 0, "CUESHEET", latin1), audio...)
+		cuesheet, err := ReadEmbeddedCue(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("ReadEmbeddedCue error: %v", err)
+		}
+		if cuesheet.Title != "Embedded Album" || cuesheet.TrackCount() != 1 {
+			t.Errorf("unexpected cuesheet: %+v", cuesheet)
+		}
+	})
+
+	t.Run("ID3v24UTF16", func(t *testing.T) {
+		data := append(id3v2Tag(4, 1, "cuesheet", embeddedCue), audio...)
+		cuesheet, err := ReadEmbeddedCue(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("ReadEmbeddedCue error: %v", err)
+		}
+		checkEmbeddedCue(t, cuesheet)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		data := append(append([]byte{}, audio...), apeTag(items, "Artist")...)
+		if _, err := ReadEmbeddedCue(bytes.NewReader(data)); !errors.Is(err, ErrNoEmbeddedCue) {
+			t.Errorf("expected ErrNoEmbeddedCue, got: %v", err)
+		}
+		if _, err := ReadEmbeddedCue(bytes.NewReader(nil)); !errors.Is(err, ErrNoEmbeddedCue) {
+			t.Errorf("expected ErrNoEmbeddedCue for empty input, got: %v", err)
+		}
+
+		// A size field claiming ~256MB must not be trusted past the end of the file
+		huge := append([]byte("ID3\x03\x00\x00\x7f\x7f\x7f\x7f"), audio...)
+		if _, err := ExtractID3v2Cuesheet(bytes.NewReader(huge)); !errors.Is(err, ErrNoEmbeddedCue) {
+			t.Errorf("expected ErrNoEmbeddedCue for an oversized tag, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package encoding
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// Charset names returned by DetectEncoding
+const (
+	UTF8        = "UTF-8"
+	Windows1251 = "windows-1251"
+	KOI8R       = "KOI8-R"
+	ShiftJIS    = "Shift_JIS"
+	Windows1252 = "windows-1252"
+)
+
+// DetectEncoding guesses the charset of raw CUE sheet bytes and returns its
+// name with a confidence score between 0 and 1.
+// UTF-8 (with or without a BOM) is recognized by validity. Otherwise
+// Shift-JIS is scored by how many bytes form well-formed double-byte
+// characters with common lead bytes, and the Cyrillic code pages by how much
+// of the text is runs of letters, split between CP1251 and KOI8-R by which
+// range holds the lowercase letters. Isolated high bytes between ASCII text
+// suggest accented Western text, reported as Windows-1252.
+func DetectEncoding(data []byte) (string, float64) {
+	if bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}) {
+		return UTF8, 1
+	}
+	high := 0
+	for _, b := range data {
+		if b >= 0x80 {
+			high++
+		}
+	}
+	if high == 0 {
+		return UTF8, 1
+	}
+	if utf8.Valid(data) {
+		return UTF8, 0.95
+	}
+
+	best, confidence := Windows1252, 0.0
+	consider := func(name string, score float64) {
+		if score > confidence {
+			best, confidence = name, score
+		}
+	}
+
+	consider(ShiftJIS, shiftJISScore(data))
+
+	// Cyrillic text is made of runs of letters in 0xC0-0xFF; CP1251 puts the
+	// lowercase letters, which dominate ordinary text, in 0xE0-0xFF and
+	// KOI8-R puts them in 0xC0-0xDF
+	inRun, letters, upperHalf := 0, 0, 0
+	for i, b := range data {
+		if b < 0x80 {
+			continue
+		}
+		if (i > 0 && data[i-1] >= 0x80) || (i+1 < len(data) && data[i+1] >= 0x80) {
+			inRun++
+		}
+		if b >= 0xC0 {
+			letters++
+			if b >= 0xE0 {
+				upperHalf++
+			}
+		}
+	}
+	runShare := float64(inRun) / float64(high)
+	letterShare := float64(letters) / float64(high)
+	if letters > 0 {
+		lower := float64(upperHalf) / float64(letters)
+		consider(Windows1251, runShare*letterShare*lower)
+		consider(KOI8R, runShare*letterShare*(1-lower))
+	}
+	consider(Windows1252, 1-runShare)
+
+	return best, confidence
+}
+
+// shiftJISScore returns the share of high bytes that belong to well-formed
+// Shift-JIS characters, counting only double-byte characters whose lead byte
+// is in 0x81-0x9F (kana and common kanji); malformed input scores 0
+func shiftJISScore(data []byte) float64 {
+	common, high := 0, 0
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case b < 0x80:
+			continue
+		case b >= 0xA1 && b <= 0xDF: // half-width katakana
+			high++
+		case (b >= 0x81 && b <= 0x9F) || (b >= 0xE0 && b <= 0xEF):
+			if i+1 >= len(data) {
+				return 0
+			}
+			t := data[i+1]
+			if t < 0x40 || t == 0x7F || t > 0xFC {
+				return 0
+			}
+			high += 2
+			if b <= 0x9F {
+				common += 2
+			}
+			i++
+		default:
+			return 0
+		}
+	}
+	if high == 0 {
+		return 0
+	}
+	return float64(common) / float64(high)
+}
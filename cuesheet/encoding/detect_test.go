@@ -0,0 +1,43 @@
+package encoding
+
+import (
+	"testing"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"ASCII", []byte("TITLE \"Album\""), UTF8},
+		{"UTF-8", []byte("TITLE \"Первый снег\""), UTF8},
+		{"UTF-8 BOM", append([]byte{0xEF, 0xBB, 0xBF}, "TITLE \"Album\""...), UTF8},
+		// "Первый снег"
+		{"CP1251", []byte("TITLE \"\xcf\xe5\xf0\xe2\xfb\xe9 \xf1\xed\xe5\xe3\""), Windows1251},
+		// "Кино - Группа крови"
+		{"CP1251 performer", []byte("PERFORMER \"\xca\xe8\xed\xee - \xc3\xf0\xf3\xef\xef\xe0 \xea\xf0\xee\xe2\xe8\""), Windows1251},
+		// "Первый снег"
+		{"KOI8-R", []byte("TITLE \"\xf0\xc5\xd2\xd7\xd9\xca \xd3\xce\xc5\xc7\""), KOI8R},
+		// "Кино - Группа крови"
+		{"KOI8-R performer", []byte("PERFORMER \"\xeb\xc9\xce\xcf - \xe7\xd2\xd5\xd0\xd0\xc1 \xcb\xd2\xcf\xd7\xc9\""), KOI8R},
+		// "ひらがな カタカナ"
+		{"Shift-JIS", []byte("TITLE \"\x82\xd0\x82\xe7\x82\xaa\x82\xc8 \x83\x4a\x83\x5e\x83\x4a\x83\x69\""), ShiftJIS},
+		// "東京"
+		{"Shift-JIS kanji", []byte("TITLE \"\x93\x8c\x8b\x9e\""), ShiftJIS},
+		// "Café Müller"
+		{"Windows-1252", []byte("TITLE \"Caf\xe9 M\xfcller\""), Windows1252},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, confidence := DetectEncoding(tt.data)
+			if name != tt.expected {
+				t.Errorf("DetectEncoding(%q) = %s (%.2f), want %s", tt.data, name, confidence, tt.expected)
+			}
+			if confidence <= 0 || confidence > 1 {
+				t.Errorf("confidence %.2f out of range", confidence)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package encoding
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// KOI8RToByte converts a Unicode character to its KOI8-R byte value.
+// Returns 0 if the character is not in KOI8-R encoding (except for actual 0x00).
+func KOI8RToByte(r rune) byte {
+	b, ok := charmap.KOI8R.EncodeRune(r)
+	if !ok {
+		return 0
+	}
+	return b
+}
+
+// DecodeMojibakeFromKOI8R fixes UTF-8 text that was incorrectly read as KOI8-R.
+// The text is returned unchanged if it cannot be mapped back to valid UTF-8.
+func DecodeMojibakeFromKOI8R(mojibake string) string {
+	buf := make([]byte, 0, len(mojibake))
+	for _, r := range mojibake {
+		b, ok := charmap.KOI8R.EncodeRune(r)
+		if !ok {
+			return mojibake
+		}
+		buf = append(buf, b)
+	}
+	if !utf8.Valid(buf) {
+		return mojibake
+	}
+	return string(buf)
+}
@@ -0,0 +1,68 @@
+package encoding
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestKOI8RToByte(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    rune
+		expected byte
+	}{
+		{"ASCII A", 'A', 0x41},
+		{"Cyrillic а", 'а', 0xC1},
+		{"Cyrillic я", 'я', 0xD1},
+		{"Cyrillic А", 'А', 0xE1},
+		{"Cyrillic Я", 'Я', 0xF1},
+		{"Cyrillic ё", 'ё', 0xA3},
+		{"Box drawing ─", '─', 0x80},
+		{"Chinese character", '中', 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := KOI8RToByte(tt.input)
+			if result != tt.expected {
+				t.Errorf("KOI8RToByte(%U) = 0x%02X, want 0x%02X", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeMojibakeFromKOI8R(t *testing.T) {
+	words := []string{"Браво", "Кино", "Группа крови", "Ёлка", "Мумий Тролль - Утекай"}
+
+	for _, word := range words {
+		t.Run(word, func(t *testing.T) {
+			// Misencode: read the UTF-8 bytes as KOI8-R
+			mojibake, err := charmap.KOI8R.NewDecoder().String(word)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mojibake == word {
+				t.Fatalf("expected %q to be garbled", word)
+			}
+			if decoded := DecodeMojibakeFromKOI8R(mojibake); decoded != word {
+				t.Errorf("DecodeMojibakeFromKOI8R(%q) = %q, want %q", mojibake, decoded, word)
+			}
+
+			// CP1251 mojibake uses characters KOI8-R lacks, so it is left alone
+			cp1251, err := charmap.Windows1251.NewDecoder().String(word)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded := DecodeMojibakeFromKOI8R(cp1251); decoded != cp1251 {
+				t.Errorf("expected CP1251 mojibake %q to be left unchanged, got %q", cp1251, decoded)
+			}
+		})
+	}
+
+	for _, s := range []string{"Hello", "Браво", "中文"} {
+		if decoded := DecodeMojibakeFromKOI8R(s); decoded != s {
+			t.Errorf("expected %q to be left unchanged, got %q", s, decoded)
+		}
+	}
+}
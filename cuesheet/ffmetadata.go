@@ -0,0 +1,59 @@
+package cuesheet
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrNotSingleFile is returned by exports that need exactly one FILE entry
+var ErrNotSingleFile = errors.New("cuesheet does not have exactly one FILE")
+
+// ffmetadataEscaper escapes the characters special to the ffmetadata format
+var ffmetadataEscaper = strings.NewReplacer("\\", "\\\\", "=", "\\=", ";", "\\;", "#", "\\#", "\n", "\\\n")
+
+// WriteFFMetadata writes the tracks of a single-file cuesheet as chapters in
+// FFmpeg's ffmetadata format, for splitting the audio with ffmpeg
+// Each chapter starts at a track's INDEX 01 and ends at the next one, with
+// TIMEBASE=1/75 so START and END are frame counts; the last chapter ends at
+// fileLength. Tracks without a title are named "Track NN"
+func WriteFFMetadata(w io.Writer, cs *Cuesheet, fileLength Frame) error {
+	if len(cs.File) != 1 {
+		return ErrNotSingleFile
+	}
+
+	ws := bufio.NewWriter(w)
+	ws.WriteString(";FFMETADATA1" + eol)
+	if cs.Title != "" {
+		ws.WriteString("title=" + ffmetadataEscaper.Replace(cs.Title) + eol)
+	}
+	if cs.Performer != "" {
+		ws.WriteString("artist=" + ffmetadataEscaper.Replace(cs.Performer) + eol)
+	}
+
+	tracks := cs.File[0].Tracks
+	for i := range tracks {
+		start, err := tracks[i].StartPosition()
+		if err != nil {
+			continue
+		}
+		end := fileLength
+		if i+1 < len(tracks) {
+			if next, err := tracks[i+1].StartPosition(); err == nil {
+				end = next
+			}
+		}
+		title := tracks[i].Title
+		if title == "" {
+			title = "Track " + FormatTrackNumber(tracks[i].TrackNumber)
+		}
+		ws.WriteString(eol + "[CHAPTER]" + eol)
+		ws.WriteString("TIMEBASE=1/" + strconv.Itoa(FramesPerSecond) + eol)
+		ws.WriteString("START=" + strconv.FormatUint(uint64(start), 10) + eol)
+		ws.WriteString("END=" + strconv.FormatUint(uint64(end), 10) + eol)
+		ws.WriteString("title=" + ffmetadataEscaper.Replace(title) + eol)
+	}
+	return ws.Flush()
+}
@@ -0,0 +1,66 @@
+package cuesheet
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteFFMetadata(t *testing.T) {
+	t.Run("Sample1", func(t *testing.T) {
+		file, err := os.Open("testdata/sample_1.cue")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		cuesheet, err := ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := os.ReadFile("testdata/sample_1.ffmetadata")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var sb strings.Builder
+		if err := WriteFFMetadata(&sb, cuesheet, DurationToFrame(15*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		if sb.String() != string(expected) {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, sb.String())
+		}
+	})
+
+	t.Run("UntitledAndEscaped", func(t *testing.T) {
+		input := "TITLE \"A=B; #1\"\nFILE \"mix.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n"
+		cuesheet, err := ReadFile(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sb strings.Builder
+		if err := WriteFFMetadata(&sb, cuesheet, 750); err != nil {
+			t.Fatal(err)
+		}
+		expected := ";FFMETADATA1\ntitle=A\\=B\\; \\#1\n\n[CHAPTER]\nTIMEBASE=1/75\nSTART=0\nEND=750\ntitle=Track 01\n"
+		if sb.String() != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, sb.String())
+		}
+	})
+
+	t.Run("MultipleFiles", func(t *testing.T) {
+		file, err := os.Open("testdata/sample_2.cue")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		cuesheet, err := ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFFMetadata(&strings.Builder{}, cuesheet, 0); !errors.Is(err, ErrNotSingleFile) {
+			t.Errorf("expected ErrNotSingleFile, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package cuesheet
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FilenameMetadataPattern extracts metadata from per-track file names such as
+// "01 - Billy Joel - She's Got A Way.flac", matched against the base name
+// without its extension. The named groups track, performer and title are
+// used; performer is optional. Replace it to match other naming schemes
+var FilenameMetadataPattern = regexp.MustCompile(
+	`^(?P<track>\d{1,3})\s*[-._)]?\s+(?:(?P<performer>.+?)\s+-\s+)?(?P<title>.+)$`)
+
+// ParseFilenameMetadata extracts the track number, performer and title encoded
+// in the file name using FilenameMetadataPattern, for backfilling missing
+// TITLE and PERFORMER values. ok is false if the name does not match
+func (f *File) ParseFilenameMetadata() (track int, performer, title string, ok bool) {
+	name := f.BaseName()
+	name = strings.TrimSuffix(name, path.Ext(name))
+
+	match := FilenameMetadataPattern.FindStringSubmatch(name)
+	if match == nil {
+		return 0, "", "", false
+	}
+	for i, group := range FilenameMetadataPattern.SubexpNames() {
+		value := strings.TrimSpace(match[i])
+		switch group {
+		case "track":
+			track, _ = strconv.Atoi(value)
+		case "performer":
+			performer = value
+		case "title":
+			title = value
+		}
+	}
+	return track, performer, title, title != ""
+}
+
+// ProposedTrackFilenames returns the file names a splitter would produce for
+// each track, in track order, by expanding pattern:
+//
+//	%n  two-digit track number
+//	%t  track title
+//	%p  track performer, falling back to the album performer
+//	%e  extension of the source FILE, without the dot
+//	%%  a literal percent sign
+//
+// Substituted values are cleaned with SanitizeFilename, replacing illegal characters with "_"
+func (c *Cuesheet) ProposedTrackFilenames(pattern string) []string {
+	var names []string
+	for i := range c.File {
+		f := &c.File[i]
+		ext := strings.TrimPrefix(path.Ext(f.BaseName()), ".")
+		for j := range f.Tracks {
+			t := &f.Tracks[j]
+			performer := t.Performer
+			if performer == "" {
+				performer = c.Performer
+			}
+			r := strings.NewReplacer(
+				"%%", "%",
+				"%n", FormatTrackNumber(t.TrackNumber),
+				"%t", SanitizeFilename(t.Title, filenameOptions),
+				"%p", SanitizeFilename(performer, filenameOptions),
+				"%e", SanitizeFilename(ext, filenameOptions),
+			)
+			names = append(names, r.Replace(pattern))
+		}
+	}
+	return names
+}
+
+// SanitizeOptions controls how SanitizeFilename cleans a name
+type SanitizeOptions struct {
+	// Replacement is written in place of each illegal character; empty strips them
+	Replacement string
+	// Windows also avoids reserved device names (CON, NUL, COM1...) and
+	// trailing dots, which Windows does not allow
+	Windows bool
+	// Transliterate converts Cyrillic letters to their Latin equivalents
+	Transliterate bool
+}
+
+// filenameOptions are used for values substituted into proposed file names
+var filenameOptions = SanitizeOptions{Replacement: "_"}
+
+// windowsReservedNames are device names Windows reserves regardless of extension
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// SanitizeFilename makes a metadata value safe to use as a file name
+// The characters / \ : * ? " < > | and control characters are replaced and
+// surrounding whitespace is trimmed
+func SanitizeFilename(s string, opts SanitizeOptions) string {
+	var sb strings.Builder
+	for _, r := range strings.TrimSpace(s) {
+		if opts.Transliterate {
+			if latin, ok := cyrillicToLatin[unicode.ToLower(r)]; ok {
+				if unicode.IsUpper(r) && latin != "" {
+					latin = strings.ToUpper(latin[:1]) + latin[1:]
+				}
+				sb.WriteString(latin)
+				continue
+			}
+		}
+		if strings.ContainsRune(`/\:*?"<>|`, r) || unicode.IsControl(r) {
+			sb.WriteString(opts.Replacement)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	name := strings.TrimSpace(sb.String())
+
+	if opts.Windows {
+		name = strings.TrimRight(name, ". ")
+		base := strings.SplitN(name, ".", 2)[0]
+		if windowsReservedNames[strings.ToUpper(strings.TrimSpace(base))] {
+			name = "_" + name
+		}
+	}
+	return name
+}
+
+// cyrillicToLatin maps lowercase Russian and Ukrainian letters to Latin
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+}
@@ -0,0 +1,117 @@
+package cuesheet
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestProposedTrackFilenames(t *testing.T) {
+	c := Cuesheet{
+		Performer: "Album Artist",
+		File: []File{
+			{
+				FileName: "C:\\Music\\image.flac",
+				FileType: "WAVE",
+				Tracks: []Track{
+					{TrackNumber: 1, Title: "AC/DC: Live?"},
+					{TrackNumber: 2, Title: "Second", Performer: "Guest"},
+				},
+			},
+			{
+				FileName: "bonus.wav",
+				FileType: "WAVE",
+				Tracks:   []Track{{TrackNumber: 3, Title: "100%"}},
+			},
+		},
+	}
+
+	got := c.ProposedTrackFilenames("%n - %p - %t.%e")
+	expected := []string{
+		"01 - Album Artist - AC_DC_ Live_.flac",
+		"02 - Guest - Second.flac",
+		"03 - Album Artist - 100%.wav",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	if got := c.ProposedTrackFilenames("%%n%n"); got[0] != "%n01" {
+		t.Errorf("expected literal percent, got: %q", got[0])
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     SanitizeOptions
+		expected string
+	}{
+		{"IllegalReplaced", `a/b\c:d*e?f"g<h>i|j`, SanitizeOptions{Replacement: "_"}, "a_b_c_d_e_f_g_h_i_j"},
+		{"IllegalStripped", "What? Why: Because", SanitizeOptions{}, "What Why Because"},
+		{"ControlAndTrim", "  Tab\there \n", SanitizeOptions{Replacement: "-"}, "Tab-here"},
+		{"ReservedKeptByDefault", "CON", SanitizeOptions{}, "CON"},
+		{"ReservedWindows", "con", SanitizeOptions{Windows: true}, "_con"},
+		{"ReservedWithExtension", "NUL.txt", SanitizeOptions{Windows: true}, "_NUL.txt"},
+		{"NotReserved", "CONTACT", SanitizeOptions{Windows: true}, "CONTACT"},
+		{"TrailingDots", "The End...", SanitizeOptions{Windows: true}, "The End"},
+		{"Transliterate", "Щедрый Вечер", SanitizeOptions{Transliterate: true}, "Shchedryy Vecher"},
+		{"SoftSign", "Мальчик", SanitizeOptions{Transliterate: true}, "Malchik"},
+		{"CyrillicKept", "Право", SanitizeOptions{}, "Право"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeFilename(tt.input, tt.opts); got != tt.expected {
+				t.Errorf("SanitizeFilename(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseFilenameMetadata(t *testing.T) {
+	tests := []struct {
+		fileName  string
+		track     int
+		performer string
+		title     string
+		ok        bool
+	}{
+		{"01 - Billy Joel - She's Got A Way.flac", 1, "Billy Joel", "She's Got A Way", true},
+		{"10 - Billy Joel - Nocturne.flac", 10, "Billy Joel", "Nocturne", true},
+		{"C:\\Rips\\03 - Billy Joel - Everybody Loves You Now.flac", 3, "Billy Joel", "Everybody Loves You Now", true},
+		{"04. Why Judy Why.wav", 4, "", "Why Judy Why", true},
+		{"05 Falling of the Rain.mp3", 5, "", "Falling of the Rain", true},
+		{"CDImage.flac", 0, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			f := File{FileName: tt.fileName}
+			track, performer, title, ok := f.ParseFilenameMetadata()
+			if track != tt.track || performer != tt.performer || title != tt.title || ok != tt.ok {
+				t.Errorf("got (%d, %q, %q, %v), expected (%d, %q, %q, %v)",
+					track, performer, title, ok, tt.track, tt.performer, tt.title, tt.ok)
+			}
+		})
+	}
+
+	t.Run("Sample2", func(t *testing.T) {
+		file, err := os.Open("testdata/sample_2.cue")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		cuesheet, err := ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range cuesheet.File {
+			f := &cuesheet.File[i]
+			track, performer, title, ok := f.ParseFilenameMetadata()
+			if !ok || track != int(f.Tracks[0].TrackNumber) || performer != "Billy Joel" || title != f.Tracks[0].Title {
+				t.Errorf("%s: got (%d, %q, %q, %v)", f.FileName, track, performer, title, ok)
+			}
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package cuesheet
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Flatten combines a multi-file cuesheet into one that references a single
+// FILE, as when per-track files are concatenated into one image
+// INDEX positions of each file are shifted by the total length of the files
+// before it, so fileLengths must hold the audio length of every FILE except
+// the last, keyed by FileName. The original cuesheet is not modified
+func (c *Cuesheet) Flatten(fileLengths map[string]time.Duration, combinedName, fileType string) (*Cuesheet, error) {
+	flat := c.clone()
+
+	tracks := []Track{}
+	var offset Frame
+	for i := range flat.File {
+		f := &flat.File[i]
+		for j := range f.Tracks {
+			for k := range f.Tracks[j].Index {
+				f.Tracks[j].Index[k].Frame += offset
+			}
+		}
+		tracks = append(tracks, f.Tracks...)
+
+		if i == len(flat.File)-1 {
+			break
+		}
+		length, ok := fileLengths[f.FileName]
+		if !ok {
+			return nil, fmt.Errorf("missing length for file %q", f.FileName)
+		}
+		offset += DurationToFrame(length)
+	}
+
+	flat.File = []File{{FileName: combinedName, FileType: fileType, Tracks: tracks}}
+	return flat, nil
+}
+
+// NormalizeAddressing rewrites INDEX positions to the given addressing mode,
+// as needed when a sheet moves between per-track files and a single image
+// Converting to absolute shifts each file by the total length of the files
+// before it; converting to file-relative undoes that. fileLengths must hold
+// the audio length of every FILE except the last, keyed by FileName
+// Sheets already in mode, or whose mode cannot be told, are left unchanged
+func (c *Cuesheet) NormalizeAddressing(mode AddressingMode, fileLengths map[string]time.Duration) error {
+	if mode != AddressingAbsolute && mode != AddressingFileRelative {
+		return fmt.Errorf("cannot normalize to %v addressing", mode)
+	}
+	current := c.IndexAddressing()
+	if current == AddressingUnknown || current == mode {
+		return nil
+	}
+
+	offsets := make([]Frame, len(c.File))
+	for i := 1; i < len(c.File); i++ {
+		name := c.File[i-1].FileName
+		length, ok := fileLengths[name]
+		if !ok {
+			return fmt.Errorf("missing length for file %q", name)
+		}
+		offsets[i] = offsets[i-1] + DurationToFrame(length)
+	}
+
+	if mode == AddressingFileRelative {
+		// Check every index first so a failed conversion leaves the sheet intact
+		for i := range c.File {
+			for _, track := range c.File[i].Tracks {
+				for _, index := range track.Index {
+					if index.Frame < offsets[i] {
+						return fmt.Errorf("track %s INDEX %s at %s is before the start of file %q",
+							FormatTrackNumber(track.TrackNumber), FormatTrackNumber(index.Number),
+							FormatFrame(index.Frame), c.File[i].FileName)
+					}
+				}
+			}
+		}
+	}
+
+	for i := range c.File {
+		tracks := c.File[i].Tracks
+		for j := range tracks {
+			for k := range tracks[j].Index {
+				if mode == AddressingAbsolute {
+					tracks[j].Index[k].Frame += offsets[i]
+				} else {
+					tracks[j].Index[k].Frame -= offsets[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RetargetFiles returns a copy of the cuesheet with every FILE pointed at a
+// converted version of the audio, e.g. ".mp3" and "MP3" after encoding FLAC
+// files to MP3. The extension of each file name is replaced by newExt (with
+// or without the leading dot) and its type by newType; everything else is kept
+// An empty newExt strips the extension, so "song.wav" becomes "song"
+func (c *Cuesheet) RetargetFiles(newExt, newType string) *Cuesheet {
+	retargeted := c.clone()
+	if newExt = strings.TrimPrefix(newExt, "."); newExt != "" {
+		newExt = "." + newExt
+	}
+	for i := range retargeted.File {
+		f := &retargeted.File[i]
+		f.FileName = strings.TrimSuffix(f.FileName, path.Ext(f.BaseName())) + newExt
+		f.FileType = newType
+	}
+	return retargeted
+}
+
+// clone returns a deep copy of the cuesheet
+func (c *Cuesheet) clone() *Cuesheet {
+	copied := *c
+	copied.Rem = cloneSlice(c.Rem)
+	copied.File = cloneSlice(c.File)
+	copied.Unknown = cloneSlice(c.Unknown)
+	for i := range copied.File {
+		f := &copied.File[i]
+		f.Tracks = cloneSlice(f.Tracks)
+		for j := range f.Tracks {
+			f.Tracks[j].Index = cloneSlice(f.Tracks[j].Index)
+			f.Tracks[j].Rem = cloneSlice(f.Tracks[j].Rem)
+		}
+	}
+	return &copied
+}
+
+// cloneSlice copies a slice, keeping nil and empty slices distinct
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
+
+// CoalesceFiles merges adjacent FILE entries with the same name by appending
+// the tracks of the later entry to the earlier one, repairing sheets with a
+// duplicated FILE line. Names must match exactly; entries that are not next
+// to each other are left alone
+func (c *Cuesheet) CoalesceFiles() {
+	if len(c.File) < 2 {
+		return
+	}
+	merged := c.File[:1]
+	for _, f := range c.File[1:] {
+		last := &merged[len(merged)-1]
+		if f.FileName == last.FileName {
+			last.Tracks = append(last.Tracks, f.Tracks...)
+			continue
+		}
+		merged = append(merged, f)
+	}
+	c.File = merged
+}
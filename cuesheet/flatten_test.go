@@ -0,0 +1,193 @@
+package cuesheet
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlatten(t *testing.T) {
+	input := "TITLE \"Album\"\n" +
+		"FILE \"01.flac\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"02.flac\" WAVE\n  TRACK 02 AUDIO\n    INDEX 00 00:00:00\n    INDEX 01 00:02:00\n" +
+		"FILE \"03.flac\" WAVE\n  TRACK 03 AUDIO\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lengths := map[string]time.Duration{
+		"01.flac": 3*time.Minute + 30*time.Second,
+		"02.flac": 4*time.Minute + 10*time.Second + 200*time.Millisecond,
+		"03.flac": 2 * time.Minute,
+	}
+	flat, err := cuesheet.Flatten(lengths, "album.flac", "WAVE")
+	if err != nil {
+		t.Fatalf("Flatten failed: %v", err)
+	}
+
+	if flat.Title != "Album" || len(flat.File) != 1 || flat.File[0].FileName != "album.flac" {
+		t.Fatalf("unexpected flattened cuesheet: %+v", flat)
+	}
+	expected := [][]string{
+		{"00:00:00"},
+		{"03:30:00", "03:32:00"},
+		{"07:40:15"},
+	}
+	tracks := flat.File[0].Tracks
+	if len(tracks) != len(expected) {
+		t.Fatalf("expected %d tracks, got: %d", len(expected), len(tracks))
+	}
+	for i, frames := range expected {
+		for k, frame := range frames {
+			if got := FormatFrame(tracks[i].Index[k].Frame); got != frame {
+				t.Errorf("track %d index %d: expected %s, got %s", i+1, k, frame, got)
+			}
+		}
+	}
+
+	if cuesheet.File[1].Tracks[0].Index[0].Frame != 0 {
+		t.Error("expected the original cuesheet to be unchanged")
+	}
+
+	delete(lengths, "02.flac")
+	if _, err := cuesheet.Flatten(lengths, "album.flac", "WAVE"); err == nil {
+		t.Error("expected an error for a missing file length")
+	}
+}
+
+func TestRetargetFiles(t *testing.T) {
+	input := "TITLE \"Album\"\n" +
+		"FILE \"01 - Intro.flac\" WAVE\n  TRACK 01 AUDIO\n    TITLE \"Intro\"\n    INDEX 01 00:00:00\n" +
+		"FILE \"C:\\\\Music\\\\v1.2\\\\02 - Song.FLAC\" WAVE\n  TRACK 02 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"noext\" WAVE\n  TRACK 03 AUDIO\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mp3 := cuesheet.RetargetFiles("mp3", "MP3")
+	expected := []string{"01 - Intro.mp3", "C:\\Music\\v1.2\\02 - Song.mp3", "noext.mp3"}
+	for i, name := range expected {
+		if mp3.File[i].FileName != name || mp3.File[i].FileType != "MP3" {
+			t.Errorf("file %d: expected %q MP3, got %q %s", i, name, mp3.File[i].FileName, mp3.File[i].FileType)
+		}
+	}
+	if mp3.Title != "Album" || mp3.File[0].Tracks[0].Title != "Intro" || mp3.TrackCount() != 3 {
+		t.Errorf("expected metadata to be kept, got: %+v", mp3)
+	}
+
+	if cuesheet.File[0].FileName != "01 - Intro.flac" || cuesheet.File[0].FileType != "WAVE" {
+		t.Error("expected the original cuesheet to be unchanged")
+	}
+	mp3.File[0].Tracks[0].Index[0].Frame = 10
+	if cuesheet.File[0].Tracks[0].Index[0].Frame != 0 {
+		t.Error("expected the copy not to share indexes with the original")
+	}
+
+	if dotted := cuesheet.RetargetFiles(".mp3", "MP3"); dotted.File[0].FileName != "01 - Intro.mp3" {
+		t.Errorf("expected a leading dot to be accepted, got: %q", dotted.File[0].FileName)
+	}
+
+	bare := cuesheet.RetargetFiles("", "WAVE")
+	for i, name := range []string{"01 - Intro", "C:\\Music\\v1.2\\02 - Song", "noext"} {
+		if bare.File[i].FileName != name {
+			t.Errorf("file %d: expected an empty extension to strip it, got: %q", i, bare.File[i].FileName)
+		}
+	}
+}
+
+func TestCoalesceFiles(t *testing.T) {
+	input := "FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"a.wav\" WAVE\n  TRACK 02 AUDIO\n    INDEX 01 04:00:00\n" +
+		"FILE \"A.wav\" WAVE\n  TRACK 03 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"b.wav\" WAVE\n  TRACK 04 AUDIO\n    INDEX 01 00:00:00\n" +
+		"FILE \"a.wav\" WAVE\n  TRACK 05 AUDIO\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cuesheet.CoalesceFiles()
+	expected := []struct {
+		name   string
+		tracks []uint
+	}{
+		{"a.wav", []uint{1, 2}},
+		{"A.wav", []uint{3}},
+		{"b.wav", []uint{4}},
+		{"a.wav", []uint{5}},
+	}
+	if len(cuesheet.File) != len(expected) {
+		t.Fatalf("expected %d files, got: %d", len(expected), len(cuesheet.File))
+	}
+	for i, e := range expected {
+		f := cuesheet.File[i]
+		var numbers []uint
+		for _, track := range f.Tracks {
+			numbers = append(numbers, track.TrackNumber)
+		}
+		if f.FileName != e.name || !reflect.DeepEqual(numbers, e.tracks) {
+			t.Errorf("file %d: expected %s %v, got %s %v", i, e.name, e.tracks, f.FileName, numbers)
+		}
+	}
+}
+
+func TestNormalizeAddressing(t *testing.T) {
+	file, err := os.Open("testdata/sample_2.cue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	cuesheet, err := ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	original := cuesheet.clone()
+
+	lengths := map[string]time.Duration{}
+	for i := range cuesheet.File {
+		lengths[cuesheet.File[i].FileName] = 3*time.Minute + time.Duration(i)*time.Second
+	}
+
+	if err := cuesheet.NormalizeAddressing(AddressingAbsolute, lengths); err != nil {
+		t.Fatalf("NormalizeAddressing failed: %v", err)
+	}
+	if mode := cuesheet.IndexAddressing(); mode != AddressingAbsolute {
+		t.Errorf("expected absolute addressing, got: %v", mode)
+	}
+	for _, tt := range []struct {
+		track uint
+		frame string
+	}{
+		{1, "00:00:00"},
+		{2, "03:00:00"},
+		{3, "06:01:00"},
+		{10, "27:36:00"},
+	} {
+		track, err := cuesheet.GetTrack(tt.track)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := FormatFrame(track.Index[0].Frame); got != tt.frame {
+			t.Errorf("track %d: expected %s, got %s", tt.track, tt.frame, got)
+		}
+	}
+
+	if err := cuesheet.NormalizeAddressing(AddressingFileRelative, lengths); err != nil {
+		t.Fatalf("NormalizeAddressing back failed: %v", err)
+	}
+	if !reflect.DeepEqual(cuesheet, original) {
+		t.Error("expected converting back to restore the original indexes")
+	}
+
+	delete(lengths, cuesheet.File[4].FileName)
+	if err := cuesheet.NormalizeAddressing(AddressingAbsolute, lengths); err == nil {
+		t.Error("expected an error for a missing file length")
+	}
+	if !reflect.DeepEqual(cuesheet, original) {
+		t.Error("expected a failed conversion to leave the cuesheet unchanged")
+	}
+}
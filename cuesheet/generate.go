@@ -0,0 +1,77 @@
+package cuesheet
+
+import (
+	"path"
+	"strings"
+)
+
+// FileTypesByExtension maps lowercase audio file extensions to the FILE type
+// written by GenerateFromFiles; unlisted extensions get WAVE
+var FileTypesByExtension = map[string]string{
+	".wav":  "WAVE",
+	".mp3":  "MP3",
+	".aif":  "AIFF",
+	".aiff": "AIFF",
+	".flac": "FLAC",
+	".ape":  "APE",
+	".wv":   "WAVPACK",
+	".ogg":  "OGG",
+	".m4a":  "MP4",
+	".bin":  "BINARY",
+}
+
+// GenerateOptions controls how GenerateFromFiles builds a cuesheet
+type GenerateOptions struct {
+	// NumberFromFilename takes track numbers from a leading number in the
+	// file names (e.g. "07 - Song.flac") instead of numbering sequentially
+	NumberFromFilename bool
+
+	// MetadataFromFilename fills TITLE and PERFORMER from the file names
+	// using FilenameMetadataPattern
+	MetadataFromFilename bool
+}
+
+// GenerateFromFiles builds a cuesheet for a folder of per-track audio files,
+// one FILE with a single TRACK starting at 00:00:00 for each name in order
+// Names are used as given, so pass them relative to where the CUE will live
+// Tracks are numbered from 1, or from the file names with NumberFromFilename;
+// a name without a number continues from the previous track. Two files with
+// the same track number return ErrDuplicateTrack
+func GenerateFromFiles(files []string, opts GenerateOptions) (*Cuesheet, error) {
+	cuesheet := &Cuesheet{File: make([]File, 0, len(files))}
+	seen := make(map[uint]bool, len(files))
+	var number uint
+
+	for _, name := range files {
+		f := File{FileName: name, FileType: fileTypeForName(name)}
+		track := Track{TrackDataType: "AUDIO", Index: []TrackIndex{{Number: 1, Frame: 0}}}
+
+		number++
+		n, performer, title, ok := f.ParseFilenameMetadata()
+		if opts.NumberFromFilename && n > 0 {
+			number = uint(n)
+		}
+		if opts.MetadataFromFilename && ok {
+			track.Title = title
+			track.Performer = performer
+		}
+		if seen[number] {
+			return nil, &TrackError{number, ErrDuplicateTrack}
+		}
+		seen[number] = true
+		track.TrackNumber = number
+
+		f.Tracks = []Track{track}
+		cuesheet.File = append(cuesheet.File, f)
+	}
+	return cuesheet, nil
+}
+
+// fileTypeForName returns the FILE type for a file name by its extension
+func fileTypeForName(name string) string {
+	ext := strings.ToLower(path.Ext(strings.ReplaceAll(name, "\\", "/")))
+	if fileType, ok := FileTypesByExtension[ext]; ok {
+		return fileType
+	}
+	return "WAVE"
+}
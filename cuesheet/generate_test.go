@@ -0,0 +1,101 @@
+package cuesheet
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFromFiles(t *testing.T) {
+	files := []string{
+		"01 - Billy Joel - She's Got A Way.flac",
+		"02 - You Can Make Me Free.FLAC",
+		"bonus/05 - Demo.mp3",
+		"hidden.wav",
+		"notes.xyz",
+	}
+
+	t.Run("Sequential", func(t *testing.T) {
+		cuesheet, err := GenerateFromFiles(files, GenerateOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedTypes := []string{"FLAC", "FLAC", "MP3", "WAVE", "WAVE"}
+		for i, f := range cuesheet.File {
+			track := f.Tracks[0]
+			if f.FileName != files[i] || f.FileType != expectedTypes[i] {
+				t.Errorf("file %d: expected %q %s, got %q %s", i, files[i], expectedTypes[i], f.FileName, f.FileType)
+			}
+			if track.TrackNumber != uint(i+1) || track.Title != "" {
+				t.Errorf("file %d: expected untitled track %d, got %d %q", i, i+1, track.TrackNumber, track.Title)
+			}
+			if start, err := track.StartPosition(); err != nil || start != 0 {
+				t.Errorf("file %d: expected INDEX 01 00:00:00, got %v %v", i, start, err)
+			}
+		}
+	})
+
+	t.Run("FromFilenames", func(t *testing.T) {
+		cuesheet, err := GenerateFromFiles(files, GenerateOptions{NumberFromFilename: true, MetadataFromFilename: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sb strings.Builder
+		if err := WriteFile(&sb, cuesheet); err != nil {
+			t.Fatal(err)
+		}
+		expected := `FILE "01 - Billy Joel - She's Got A Way.flac" FLAC
+  TRACK 01 AUDIO
+    TITLE "She's Got A Way"
+    PERFORMER "Billy Joel"
+    INDEX 01 00:00:00
+FILE "02 - You Can Make Me Free.FLAC" FLAC
+  TRACK 02 AUDIO
+    TITLE "You Can Make Me Free"
+    INDEX 01 00:00:00
+FILE "bonus/05 - Demo.mp3" MP3
+  TRACK 05 AUDIO
+    TITLE Demo
+    INDEX 01 00:00:00
+FILE hidden.wav WAVE
+  TRACK 06 AUDIO
+    INDEX 01 00:00:00
+FILE notes.xyz WAVE
+  TRACK 07 AUDIO
+    INDEX 01 00:00:00
+`
+		if sb.String() != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, sb.String())
+		}
+	})
+
+	t.Run("ValidTypes", func(t *testing.T) {
+		cuesheet, err := GenerateFromFiles(files, GenerateOptions{NumberFromFilename: true, MetadataFromFilename: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if errs := cuesheet.Validate(); len(errs) != 0 {
+			t.Errorf("expected a generated sheet to validate, got: %v", errs)
+		}
+
+		var sb strings.Builder
+		if err := WriteFile(&sb, cuesheet); err != nil {
+			t.Fatal(err)
+		}
+		strict, err := ReadFileStrict(strings.NewReader(sb.String()))
+		if err != nil {
+			t.Fatalf("expected a generated sheet to pass strict reading, got: %v", err)
+		}
+		if !reflect.DeepEqual(strict, cuesheet) {
+			t.Errorf("round-trip mismatch:\n%+v\n%+v", cuesheet, strict)
+		}
+	})
+
+	t.Run("DuplicateNumber", func(t *testing.T) {
+		_, err := GenerateFromFiles([]string{"01 - A.flac", "01 - B.flac"}, GenerateOptions{NumberFromFilename: true})
+		if !errors.Is(err, ErrDuplicateTrack) {
+			t.Errorf("expected ErrDuplicateTrack, got: %v", err)
+		}
+	})
+}
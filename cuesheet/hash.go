@@ -0,0 +1,37 @@
+package cuesheet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// StructuralHash returns a hex SHA-256 digest of the disc layout: the file
+// boundaries, track numbers, INDEX positions, PREGAP and POSTGAP
+// FILE names, CD-TEXT, REM and other metadata are ignored, so renaming or
+// retagging the audio keeps the hash; two sheets with the same hash describe
+// the same disc structure
+func (c *Cuesheet) StructuralHash() string {
+	var sb strings.Builder
+	writeFrame := func(name string, f Frame) {
+		sb.WriteString(name + " " + strconv.FormatUint(uint64(f), 10) + "\n")
+	}
+
+	writeFrame("PREGAP", c.Pregap)
+	writeFrame("POSTGAP", c.Postgap)
+	for i := range c.File {
+		sb.WriteString("FILE\n")
+		for _, track := range c.File[i].Tracks {
+			sb.WriteString("TRACK " + FormatTrackNumber(track.TrackNumber) + "\n")
+			writeFrame("PREGAP", track.Pregap)
+			for _, index := range track.Index {
+				writeFrame("INDEX "+FormatTrackNumber(index.Number), index.Frame)
+			}
+			writeFrame("POSTGAP", track.Postgap)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(sb.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,34 @@
+package cuesheet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStructuralHash(t *testing.T) {
+	input := "TITLE \"Album\"\nFILE \"album.flac\" WAVE\n  TRACK 01 AUDIO\n    TITLE \"One\"\n    INDEX 01 00:00:00\n  TRACK 02 AUDIO\n    INDEX 00 03:58:00\n    INDEX 01 04:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := cuesheet.StructuralHash()
+	if len(hash) != 64 {
+		t.Fatalf("expected a hex SHA-256 digest, got: %q", hash)
+	}
+
+	renamed, err := ReadFile(strings.NewReader(strings.NewReplacer("album.flac", "Artist - Album.wav", "One", "First").Replace(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.StructuralHash() != hash {
+		t.Errorf("expected renaming the FILE and retitling to keep the hash")
+	}
+
+	moved, err := ReadFile(strings.NewReader(strings.Replace(input, "04:00:00", "04:00:01", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.StructuralHash() == hash {
+		t.Errorf("expected moving an INDEX to change the hash")
+	}
+}
@@ -0,0 +1,262 @@
+package cuesheet
+
+import "time"
+
+// Layout describes how tracks are distributed over FILE entries
+type Layout int
+
+const (
+	LayoutNone         Layout = iota // No tracks
+	LayoutSingleFile                 // All tracks in one file (image + CUE)
+	LayoutFilePerTrack               // Exactly one track per file
+	LayoutMultiFile                  // Several files, some holding more than one track
+)
+
+func (l Layout) String() string {
+	switch l {
+	case LayoutSingleFile:
+		return "single-file"
+	case LayoutFilePerTrack:
+		return "file-per-track"
+	case LayoutMultiFile:
+		return "multi-file"
+	default:
+		return "none"
+	}
+}
+
+// AddressingMode describes how INDEX times relate to the audio files
+type AddressingMode int
+
+const (
+	AddressingUnknown      AddressingMode = iota // No indexes to judge from
+	AddressingAbsolute                           // Times increase across the whole disc (single file or continuous)
+	AddressingFileRelative                       // Times restart in each FILE
+)
+
+func (m AddressingMode) String() string {
+	switch m {
+	case AddressingAbsolute:
+		return "absolute"
+	case AddressingFileRelative:
+		return "file-relative"
+	default:
+		return "unknown"
+	}
+}
+
+// CueStats is a one-shot summary of a cuesheet
+type CueStats struct {
+	TrackCount    int
+	FileCount     int
+	TotalDuration time.Duration
+	HasHTOA       bool // Hidden track one audio before track 1 INDEX 01
+	HasPregaps    bool // Any track with a pregap (PREGAP or INDEX 00)
+	Layout        Layout
+	HasISRC       bool // Any track with an ISRC
+	HasReplayGain bool // Any album or track REPLAYGAIN_* REM field
+}
+
+// Layout reports how the tracks are distributed over FILE entries
+func (c *Cuesheet) Layout() Layout {
+	tracks := c.TrackCount()
+	switch {
+	case tracks == 0:
+		return LayoutNone
+	case len(c.File) == 1:
+		return LayoutSingleFile
+	case tracks == len(c.File):
+		for i := range c.File {
+			if len(c.File[i].Tracks) != 1 {
+				return LayoutMultiFile
+			}
+		}
+		return LayoutFilePerTrack
+	default:
+		return LayoutMultiFile
+	}
+}
+
+// IndexAddressing infers whether INDEX times are relative to their FILE or
+// absolute across the disc. A FILE whose first index does not come after
+// every index of the preceding files restarts the clock, so the sheet is
+// file-relative; otherwise times increase monotonically and are absolute
+func (c *Cuesheet) IndexAddressing() AddressingMode {
+	mode := AddressingUnknown
+	var last Frame
+	for i := range c.File {
+		first := true
+		for j := range c.File[i].Tracks {
+			for _, index := range c.File[i].Tracks[j].Index {
+				if first && mode != AddressingUnknown && index.Frame <= last {
+					return AddressingFileRelative
+				}
+				first = false
+				mode = AddressingAbsolute
+				if index.Frame > last {
+					last = index.Frame
+				}
+			}
+		}
+	}
+	return mode
+}
+
+// HasHTOA returns true if the first track has audio in its pregap
+// (INDEX 00 before INDEX 01), commonly used for hidden tracks
+func (c *Cuesheet) HasHTOA() bool {
+	for i := range c.File {
+		if len(c.File[i].Tracks) == 0 {
+			continue
+		}
+		track := &c.File[i].Tracks[0]
+		pregap, ok := track.GetPregapIndex()
+		if !ok {
+			return false
+		}
+		start, err := track.StartPosition()
+		return err == nil && start > pregap.Frame
+	}
+	return false
+}
+
+// FirstTrackStartsCorrectly checks that the first track covers the start of
+// its file: INDEX 01 at 00:00:00, or a hidden track (HTOA) with INDEX 00 at
+// 00:00:00 before a later INDEX 01. Otherwise the audio before the first index
+// belongs to no track, usually a sign of a wrong offset in a single-file rip
+// When the check fails, the message explains the problem
+func (c *Cuesheet) FirstTrackStartsCorrectly() (bool, string) {
+	for i := range c.File {
+		if len(c.File[i].Tracks) == 0 {
+			continue
+		}
+		track := &c.File[i].Tracks[0]
+		number := FormatTrackNumber(track.TrackNumber)
+		start, err := track.StartPosition()
+		if err != nil {
+			return false, "track " + number + " has no INDEX 01"
+		}
+		if start == 0 {
+			return true, ""
+		}
+		pregap, ok := track.GetPregapIndex()
+		if !ok {
+			return false, "track " + number + " starts at " + FormatFrame(start) +
+				" without an INDEX 00, so the audio before it belongs to no track"
+		}
+		if pregap.Frame != 0 {
+			return false, "track " + number + " INDEX 00 starts at " + FormatFrame(pregap.Frame) +
+				", so the audio before it belongs to no track"
+		}
+		return true, ""
+	}
+	return false, "no tracks"
+}
+
+// Gap is silence or pregap audio between two consecutive tracks
+type Gap struct {
+	TrackNumber uint  // Track the gap precedes
+	Length      Frame // Combined POSTGAP of the previous track and pregap of this one
+}
+
+// Gaps returns the gaps between consecutive tracks: the pregap of every track
+// after the first (INDEX 00 or PREGAP) plus the POSTGAP of the track before it
+// The pregap of the first track is not between tracks and is not included
+func (c *Cuesheet) Gaps() []Gap {
+	var gaps []Gap
+	var previous *Track
+	for i := range c.File {
+		for j := range c.File[i].Tracks {
+			track := &c.File[i].Tracks[j]
+			if previous != nil {
+				length := previous.Postgap + track.Pregap
+				if idx00, ok := track.GetPregapIndex(); ok {
+					if idx01, err := track.GetStartIndex(); err == nil && idx01.Frame > idx00.Frame {
+						length += idx01.Frame - idx00.Frame
+					}
+				}
+				if length > 0 {
+					gaps = append(gaps, Gap{TrackNumber: track.TrackNumber, Length: length})
+				}
+			}
+			previous = track
+		}
+	}
+	return gaps
+}
+
+// IsGapless returns true if every track ends exactly where the next begins,
+// so a player needs no gap or crossfade handling
+func (c *Cuesheet) IsGapless() bool {
+	return len(c.Gaps()) == 0
+}
+
+// Stats summarizes the cuesheet
+func (c *Cuesheet) Stats() CueStats {
+	stats := CueStats{
+		TrackCount:    c.TrackCount(),
+		FileCount:     len(c.File),
+		TotalDuration: c.TotalDuration(),
+		HasHTOA:       c.HasHTOA(),
+		Layout:        c.Layout(),
+	}
+
+	for i := range c.File {
+		for j := range c.File[i].Tracks {
+			track := &c.File[i].Tracks[j]
+			if track.Isrc != "" {
+				stats.HasISRC = true
+			}
+			if track.PregapDuration() > 0 {
+				stats.HasPregaps = true
+			}
+			for _, rem := range track.Rem {
+				if field, ok := ParseRemComment(rem); ok && field.Type.IsReplayGain() {
+					stats.HasReplayGain = true
+				}
+			}
+		}
+	}
+
+	for _, field := range c.GetRemFields() {
+		if field.Type.IsReplayGain() {
+			stats.HasReplayGain = true
+		}
+	}
+
+	return stats
+}
+
+// CompletenessReport lists which metadata a commercial disc is expected to
+// carry: CATALOG, REM DATE, REM GENRE and an ISRC for every track
+type CompletenessReport struct {
+	Present []string // e.g. "CATALOG", "track 01 ISRC"
+	Missing []string
+	Percent float64 // Share of expected fields present, 0-100
+}
+
+// MetadataCompleteness scores how much of the expected disc metadata is present
+func (c *Cuesheet) MetadataCompleteness() CompletenessReport {
+	var report CompletenessReport
+	check := func(name string, ok bool) {
+		if ok {
+			report.Present = append(report.Present, name)
+		} else {
+			report.Missing = append(report.Missing, name)
+		}
+	}
+
+	_, hasDate := c.GetRemValue(RemDate)
+	_, hasGenre := c.GetRemValue(RemGenre)
+	check("CATALOG", c.Catalog != "")
+	check("DATE", hasDate)
+	check("GENRE", hasGenre)
+	for i := range c.File {
+		for _, track := range c.File[i].Tracks {
+			check("track "+FormatTrackNumber(track.TrackNumber)+" ISRC", track.Isrc != "")
+		}
+	}
+
+	report.Percent = 100 * float64(len(report.Present)) / float64(len(report.Present)+len(report.Missing))
+	return report
+}
@@ -0,0 +1,211 @@
+package cuesheet
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("Sample2", func(t *testing.T) {
+		file, err := os.Open("testdata/sample_2.cue")
+		if err != nil {
+			t.Fatalf("failed to open sample_2.cue: %v", err)
+		}
+		defer file.Close()
+
+		cuesheet, err := ReadFile(file)
+		if err != nil {
+			t.Fatalf("ReadFile failed: %v", err)
+		}
+
+		stats := cuesheet.Stats()
+		if stats.TrackCount != 10 {
+			t.Errorf("expected 10 tracks, got: %d", stats.TrackCount)
+		}
+		if stats.FileCount != 10 {
+			t.Errorf("expected 10 files, got: %d", stats.FileCount)
+		}
+		if stats.Layout != LayoutFilePerTrack {
+			t.Errorf("expected file-per-track layout, got: %v", stats.Layout)
+		}
+		if !stats.HasISRC {
+			t.Error("expected ISRCs to be present")
+		}
+		if !stats.HasReplayGain {
+			t.Error("expected ReplayGain to be present")
+		}
+		if stats.HasHTOA || stats.HasPregaps {
+			t.Errorf("expected no HTOA or pregaps, got: %+v", stats)
+		}
+	})
+
+	t.Run("SingleFileWithHTOA", func(t *testing.T) {
+		input := `FILE "image.wav" WAVE
+  TRACK 01 AUDIO
+    INDEX 00 00:00:00
+    INDEX 01 01:30:00
+  TRACK 02 AUDIO
+    INDEX 01 05:00:00
+`
+		cuesheet, err := ReadFile(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("ReadFile error: %v", err)
+		}
+		stats := cuesheet.Stats()
+		if stats.Layout != LayoutSingleFile {
+			t.Errorf("expected single-file layout, got: %v", stats.Layout)
+		}
+		if !stats.HasHTOA || !stats.HasPregaps {
+			t.Errorf("expected HTOA and pregaps, got: %+v", stats)
+		}
+		if stats.HasISRC || stats.HasReplayGain {
+			t.Errorf("expected no ISRC or ReplayGain, got: %+v", stats)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		empty := Cuesheet{}
+		if stats := empty.Stats(); stats.Layout != LayoutNone || stats.TrackCount != 0 {
+			t.Errorf("unexpected stats for empty cuesheet: %+v", stats)
+		}
+	})
+}
+
+func TestIndexAddressing(t *testing.T) {
+	for _, tt := range []struct {
+		file     string
+		expected AddressingMode
+	}{
+		{"testdata/sample_1.cue", AddressingAbsolute},
+		{"testdata/sample_2.cue", AddressingFileRelative},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			file, err := os.Open(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			cuesheet, err := ReadFile(file)
+			if err != nil {
+				t.Fatalf("ReadFile failed: %v", err)
+			}
+			if mode := cuesheet.IndexAddressing(); mode != tt.expected {
+				t.Errorf("expected %v, got: %v", tt.expected, mode)
+			}
+		})
+	}
+
+	t.Run("ContinuousMultiFile", func(t *testing.T) {
+		input := "FILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\nFILE \"b.wav\" WAVE\n  TRACK 02 AUDIO\n    INDEX 01 04:00:00\n"
+		cuesheet, err := ReadFile(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := cuesheet.IndexAddressing(); mode != AddressingAbsolute {
+			t.Errorf("expected absolute, got: %v", mode)
+		}
+	})
+
+	t.Run("NoIndexes", func(t *testing.T) {
+		var c Cuesheet
+		if mode := c.IndexAddressing(); mode != AddressingUnknown {
+			t.Errorf("expected unknown, got: %v", mode)
+		}
+	})
+}
+
+func TestFirstTrackStartsCorrectly(t *testing.T) {
+	tests := []struct {
+		name    string
+		indexes string
+		ok      bool
+		message string
+	}{
+		{"StartsAtZero", "    INDEX 01 00:00:00\n", true, ""},
+		{"HTOA", "    INDEX 00 00:00:00\n    INDEX 01 01:12:40\n", true, ""},
+		{"NonzeroWithoutHTOA", "    INDEX 01 00:00:32\n", false, "track 01 starts at 00:00:32 without an INDEX 00"},
+		{"PregapNotAtZero", "    INDEX 00 00:01:00\n    INDEX 01 00:03:00\n", false, "track 01 INDEX 00 starts at 00:01:00"},
+		{"MissingIndex01", "", false, "track 01 has no INDEX 01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "FILE \"image.wav\" WAVE\n  TRACK 01 AUDIO\n" + tt.indexes + "  TRACK 02 AUDIO\n    INDEX 01 04:00:00\n"
+			cuesheet, err := ReadFile(strings.NewReader(input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, message := cuesheet.FirstTrackStartsCorrectly()
+			if ok != tt.ok || !strings.HasPrefix(message, tt.message) || (tt.ok && message != "") {
+				t.Errorf("FirstTrackStartsCorrectly() = (%v, %q), expected (%v, %q...)", ok, message, tt.ok, tt.message)
+			}
+		})
+	}
+
+	var empty Cuesheet
+	if ok, message := empty.FirstTrackStartsCorrectly(); ok || message != "no tracks" {
+		t.Errorf("expected failure for an empty cuesheet, got: (%v, %q)", ok, message)
+	}
+}
+
+func TestGaps(t *testing.T) {
+	gapless := "FILE \"image.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 00 00:00:00\n    INDEX 01 00:01:00\n  TRACK 02 AUDIO\n    INDEX 01 03:00:00\n  TRACK 03 AUDIO\n    INDEX 01 06:00:00\n"
+	withGaps := "FILE \"image.wav\" WAVE\n  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n    POSTGAP 00:01:00\n  TRACK 02 AUDIO\n    INDEX 00 03:00:00\n    INDEX 01 03:02:00\n  TRACK 03 AUDIO\n    PREGAP 00:00:07\n    INDEX 01 06:00:00\n"
+
+	cuesheet, err := ReadFile(strings.NewReader(gapless))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cuesheet.IsGapless() {
+		t.Errorf("expected gapless album (HTOA ignored), got gaps: %+v", cuesheet.Gaps())
+	}
+
+	cuesheet, err = ReadFile(strings.NewReader(withGaps))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cuesheet.IsGapless() {
+		t.Error("expected gaps to be detected")
+	}
+	expected := []Gap{{TrackNumber: 2, Length: 75 + 150}, {TrackNumber: 3, Length: 7}}
+	if gaps := cuesheet.Gaps(); len(gaps) != 2 || gaps[0] != expected[0] || gaps[1] != expected[1] {
+		t.Errorf("expected %+v, got %+v", expected, gaps)
+	}
+}
+
+func TestMetadataCompleteness(t *testing.T) {
+	tests := []struct {
+		path    string
+		present int
+		missing int
+	}{
+		{"testdata/sample_1.cue", 2, 4},  // DATE, GENRE; no CATALOG or ISRCs
+		{"testdata/sample_2.cue", 12, 1}, // everything but CATALOG
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			file, err := os.Open(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			cuesheet, err := ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report := cuesheet.MetadataCompleteness()
+			if len(report.Present) != tt.present || len(report.Missing) != tt.missing {
+				t.Errorf("expected %d present and %d missing, got: %v / %v", tt.present, tt.missing, report.Present, report.Missing)
+			}
+			expected := 100 * float64(tt.present) / float64(tt.present+tt.missing)
+			if report.Percent != expected {
+				t.Errorf("expected %.1f%%, got: %.1f%%", expected, report.Percent)
+			}
+			if report.Missing[0] != "CATALOG" {
+				t.Errorf("expected CATALOG to be missing, got: %v", report.Missing)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package cuesheet
+
+// EventKind identifies what an Event describes
+type EventKind int
+
+const (
+	EventHeader     EventKind = iota // Album-level command before or between FILE blocks
+	EventFile                        // FILE command; File holds its name and type
+	EventTrack                       // TRACK command; Track holds number, data type and comment
+	EventTrackField                  // Track-level command other than INDEX
+	EventIndex                       // INDEX command; Index holds its number and position
+	EventUnknown                     // Unrecognized command; Value holds the whole line
+)
+
+// Event is one element of a cuesheet reported by ParseStream
+// Header and track field events carry their value in Value, Frame (PREGAP,
+// POSTGAP) or Flags (FLAGS) depending on Command
+type Event struct {
+	Kind    EventKind
+	Line    int    // 1-based line number of the command
+	Command string // CUE command, e.g. "TITLE" or "INDEX"
+	Value   string
+	Frame   Frame
+	Flags   Flags
+	File    File       // EventFile only; Tracks is always empty
+	Track   Track      // EventTrack only; fields other than number, data type and comment are empty
+	Index   TrackIndex // EventIndex only
+}
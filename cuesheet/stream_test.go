@@ -0,0 +1,111 @@
+package cuesheet
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseStream(t *testing.T) {
+	input := "REM DATE 1971\nTITLE \"Album\"\nFILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    FLAGS DCP PRE\n    TITLE \"One\"\n    INDEX 00 00:00:00\n    INDEX 01 00:02:00\n  TRACK 02 AUDIO\n    INDEX 01 03:00:00\n"
+
+	var events []string
+	err := ParseStream(strings.NewReader(input), func(ev Event) error {
+		switch ev.Kind {
+		case EventHeader:
+			events = append(events, "header "+ev.Command+"="+ev.Value)
+		case EventFile:
+			events = append(events, "file "+ev.File.FileName+" "+ev.File.FileType)
+		case EventTrack:
+			events = append(events, "track "+FormatTrackNumber(ev.Track.TrackNumber)+" "+ev.Track.TrackDataType)
+		case EventTrackField:
+			events = append(events, "field "+ev.Command+"="+ev.Value+" "+strconv.Itoa(int(ev.Flags)))
+		case EventIndex:
+			events = append(events, "index "+FormatTrackNumber(ev.Index.Number)+" "+FormatFrame(ev.Index.Frame))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"header REM=DATE 1971",
+		"header TITLE=Album",
+		"file a.wav WAVE",
+		"track 01 AUDIO",
+		"field FLAGS= " + strconv.Itoa(Dcp|Pre),
+		"field TITLE=One 0",
+		"index 00 00:00:00",
+		"index 01 00:02:00",
+		"track 02 AUDIO",
+		"index 01 03:00:00",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events:\n%q\ngot:\n%q", expected, events)
+	}
+
+	t.Run("Abort", func(t *testing.T) {
+		stop := errors.New("stop")
+		tracks := 0
+		err := ParseStream(strings.NewReader(input), func(ev Event) error {
+			if ev.Kind == EventTrack {
+				tracks++
+				return stop
+			}
+			return nil
+		})
+		if err != stop || tracks != 1 {
+			t.Errorf("expected the handler error after one track, got: %v after %d tracks", err, tracks)
+		}
+	})
+
+	t.Run("LineNumbers", func(t *testing.T) {
+		var lines []int
+		err := ParseStream(strings.NewReader(input), func(ev Event) error {
+			if ev.Kind == EventIndex {
+				lines = append(lines, ev.Line)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(lines, []int{7, 8, 10}) {
+			t.Errorf("expected INDEX lines 7, 8, 10, got: %v", lines)
+		}
+	})
+
+	t.Run("MatchesReadFile", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/sample_2.cue")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cuesheet, err := ReadFile(strings.NewReader(string(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		indexes := 0
+		err = ParseStream(strings.NewReader(string(data)), func(ev Event) error {
+			if ev.Kind == EventIndex {
+				indexes++
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		total := 0
+		for _, f := range cuesheet.File {
+			for _, track := range f.Tracks {
+				total += len(track.Index)
+			}
+		}
+		if indexes != total {
+			t.Errorf("expected %d INDEX events, got: %d", total, indexes)
+		}
+	})
+}
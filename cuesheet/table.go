@@ -0,0 +1,89 @@
+package cuesheet
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TableOptions controls the layout of FormatTable
+type TableOptions struct {
+	// TitleWidth and PerformerWidth are the column widths in characters;
+	// longer values are truncated with "...". Zero means 30
+	TitleWidth     int
+	PerformerWidth int
+
+	// FileLengths holds audio lengths keyed by FileName so the last track of
+	// each file gets a duration; without it that duration is "unknown"
+	FileLengths map[string]time.Duration
+}
+
+// FormatTable returns the tracks as a text table with track number, title,
+// performer and duration columns, one row per track
+// A track without a performer shows the album performer; a track's duration
+// runs to the next track's INDEX 01 in the same file, never across a FILE
+func (c *Cuesheet) FormatTable(opts TableOptions) string {
+	titleWidth, performerWidth := opts.TitleWidth, opts.PerformerWidth
+	if titleWidth <= 0 {
+		titleWidth = 30
+	}
+	if performerWidth <= 0 {
+		performerWidth = 30
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Track | " + padCell("Title", titleWidth) + " | " + padCell("Performer", performerWidth) + " | Duration" + eol)
+	sb.WriteString("------|" + strings.Repeat("-", titleWidth+2) + "|" + strings.Repeat("-", performerWidth+2) + "|----------" + eol)
+
+	for i := range c.File {
+		tracks := c.File[i].Tracks
+		for j := range tracks {
+			track := &tracks[j]
+
+			duration := "unknown"
+			if j+1 < len(tracks) {
+				if next, err := tracks[j+1].StartPosition(); err == nil {
+					duration = formatMinutes(track.Duration(next))
+				}
+			} else if length, ok := opts.FileLengths[c.File[i].FileName]; ok {
+				duration = formatMinutes(track.Duration(DurationToFrame(length)))
+			}
+
+			title := track.Title
+			if title == "" {
+				title = "-"
+			}
+			performer := track.Performer
+			if performer == "" {
+				performer = c.Performer
+			}
+			if performer == "" {
+				performer = "-"
+			}
+
+			sb.WriteString(fmt.Sprintf("%5d | %s | %s | %s", track.TrackNumber,
+				padCell(title, titleWidth), padCell(performer, performerWidth), duration) + eol)
+		}
+	}
+	return sb.String()
+}
+
+// padCell truncates s to width characters, ending it with "..." when cut,
+// and pads it with spaces to exactly width characters
+// Columns narrower than 4 characters are cut without the ellipsis
+func padCell(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n > width {
+		if width < 4 {
+			s = truncateRunes(s, width)
+		} else {
+			s = truncateRunes(s, width-3) + "..."
+		}
+	}
+	return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+}
+
+// formatMinutes formats a duration as MM:SS, truncating fractions of a second
+func formatMinutes(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -0,0 +1,72 @@
+package cuesheet
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatTable(t *testing.T) {
+	file, err := os.Open("testdata/sample_1.cue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	cuesheet, err := ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Track | Title                          | Performer                      | Duration\n" +
+		"------|--------------------------------|--------------------------------|----------\n" +
+		"    1 | First Song                     | Artist Name                    | 05:30\n" +
+		"    2 | Second Song                    | Artist Name                    | 04:45\n" +
+		"    3 | Third Song                     | Artist Name                    | unknown\n"
+	if table := cuesheet.FormatTable(TableOptions{}); table != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, table)
+	}
+
+	table := cuesheet.FormatTable(TableOptions{
+		TitleWidth:  8,
+		FileLengths: map[string]time.Duration{"Full_Mix.wav": 15 * time.Minute},
+	})
+	if !strings.Contains(table, "    2 | Secon... | Artist Name") {
+		t.Errorf("expected titles truncated to 8 characters, got:\n%s", table)
+	}
+	if !strings.HasSuffix(table, "| 04:44\n") {
+		t.Errorf("expected the last track to end at the file length, got:\n%s", table)
+	}
+}
+
+func TestFormatTableFileBoundary(t *testing.T) {
+	input := "FILE \"01.wav\" WAVE\n  TRACK 01 AUDIO\n    TITLE \"Песня\"\n    INDEX 01 00:00:00\n" +
+		"FILE \"02.wav\" WAVE\n  TRACK 02 AUDIO\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ReadFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := cuesheet.FormatTable(TableOptions{TitleWidth: 6, PerformerWidth: 6})
+	expected := "Track | Title  | Per... | Duration\n" +
+		"------|--------|--------|----------\n" +
+		"    1 | Песня  | -      | unknown\n" +
+		"    2 | -      | -      | unknown\n"
+	if table != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, table)
+	}
+}
+
+func TestFormatTableNarrowColumns(t *testing.T) {
+	input := "PERFORMER \"Artist\"\nFILE \"a.wav\" WAVE\n  TRACK 01 AUDIO\n    TITLE \"Первая песня\"\n    INDEX 01 00:00:00\n"
+	cuesheet, err := ParseString(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for width := 1; width <= 3; width++ {
+		table := cuesheet.FormatTable(TableOptions{TitleWidth: width, PerformerWidth: width})
+		row := "    1 | " + string([]rune("Первая")[:width]) + " | " + "Artist"[:width] + " | unknown\n"
+		if !strings.HasSuffix(table, row) {
+			t.Errorf("width %d: expected row %q, got:\n%s", width, row, table)
+		}
+	}
+}
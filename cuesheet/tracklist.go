@@ -0,0 +1,104 @@
+package cuesheet
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// tracklistNumber matches a leading track number such as "01." or "2)"
+	tracklistNumber = regexp.MustCompile(`^(\d{1,3})[.)]?\s+(.*)$`)
+	// tracklistTimeFirst matches "0:00 Title" and "[03:45] Title"
+	tracklistTimeFirst = regexp.MustCompile(`^[\[(]?(\d{1,3}(?::\d{2}){1,2})[\])]?(?:\s+[-–]?\s*(.*))?$`)
+	// tracklistTimeLast matches "Title - 3:45" and "Title (3:45)"
+	tracklistTimeLast = regexp.MustCompile(`^(.*?)\s*[-–]?\s*[\[(]?(\d{1,3}(?::\d{2}){1,2})[\])]?$`)
+)
+
+// ParseTracklist builds a single-file cuesheet from a plain text tracklist
+// such as the ones posted with online videos:
+//
+//  01. Intro - 0:00
+//  02. Song Title - 3:45
+//     [1:02:10] Third Title
+//
+// Each non-empty line holds an optional track number, a title and a start time
+// in M:SS, MM:SS or H:MM:SS form, either before or after the title. The start
+// time becomes INDEX 01; it may only be omitted for the first track, which then
+// starts at 00:00:00. Tracks without a number are numbered sequentially
+func ParseTracklist(r io.Reader, fileName, fileType string) (*Cuesheet, error) {
+	scanner := bufio.NewScanner(r)
+	tracks := []Track{}
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" {
+			continue
+		}
+
+		number := uint(len(tracks) + 1)
+		if m := tracklistNumber.FindStringSubmatch(line); m != nil && !tracklistTimeFirst.MatchString(line) {
+			n, _ := strconv.Atoi(m[1])
+			number = uint(n)
+			line = m[2]
+		}
+
+		title, start := line, ""
+		if m := tracklistTimeFirst.FindStringSubmatch(line); m != nil {
+			start, title = m[1], m[2]
+		} else if m := tracklistTimeLast.FindStringSubmatch(line); m != nil {
+			title, start = m[1], m[2]
+		}
+
+		var frame Frame
+		switch {
+		case start != "":
+			var err error
+			if frame, err = parseTracklistTime(start); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+		case len(tracks) > 0:
+			return nil, fmt.Errorf("line %d: track %d has no start time", lineNumber, number)
+		}
+
+		if len(tracks) > 0 && frame < tracks[len(tracks)-1].Index[0].Frame {
+			return nil, fmt.Errorf("line %d: start time %s is before the previous track", lineNumber, start)
+		}
+
+		tracks = append(tracks, Track{
+			TrackNumber:   number,
+			TrackDataType: "AUDIO",
+			Title:         strings.TrimSpace(title),
+			Index:         []TrackIndex{{Number: 1, Frame: frame}},
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Cuesheet{
+		File: []File{{FileName: fileName, FileType: fileType, Tracks: tracks}},
+	}, nil
+}
+
+// parseTracklistTime converts M:SS, MM:SS or H:MM:SS to a frame position
+func parseTracklistTime(s string) (Frame, error) {
+	var seconds uint64
+	parts := strings.Split(s, ":")
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+		if i > 0 && n >= 60 {
+			return 0, fmt.Errorf("invalid time %q: field out of range", s)
+		}
+		seconds = seconds*60 + n
+	}
+	return Frame(seconds * FramesPerSecond), nil
+}
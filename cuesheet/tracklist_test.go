@@ -0,0 +1,88 @@
+package cuesheet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTracklist(t *testing.T) {
+	type want struct {
+		number uint
+		title  string
+		start  string
+	}
+	tests := []struct {
+		name     string
+		input    string
+		expected []want
+	}{
+		{
+			name:  "NumberedTimeLast",
+			input: "01. Intro - 0:00\n02. Second Song - 3:45\n03. Finale - 12:01\n",
+			expected: []want{
+				{1, "Intro", "00:00:00"},
+				{2, "Second Song", "03:45:00"},
+				{3, "Finale", "12:01:00"},
+			},
+		},
+		{
+			name:  "TimeFirst",
+			input: "0:00 Opening\n\n[04:30] Middle - Part 2\n1:02:10 Long Closer\n",
+			expected: []want{
+				{1, "Opening", "00:00:00"},
+				{2, "Middle - Part 2", "04:30:00"},
+				{3, "Long Closer", "62:10:00"},
+			},
+		},
+		{
+			name:  "Parenthesized",
+			input: "1) First (0:00)\n2) Second (5:07)\n",
+			expected: []want{
+				{1, "First", "00:00:00"},
+				{2, "Second", "05:07:00"},
+			},
+		},
+		{
+			name:  "FirstTimeOptional",
+			input: "Untimed Opener\nNext One - 2:00\n",
+			expected: []want{
+				{1, "Untimed Opener", "00:00:00"},
+				{2, "Next One", "02:00:00"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cuesheet, err := ParseTracklist(strings.NewReader(tt.input), "mix.flac", "WAVE")
+			if err != nil {
+				t.Fatalf("ParseTracklist failed: %v", err)
+			}
+			if len(cuesheet.File) != 1 || cuesheet.File[0].FileName != "mix.flac" || cuesheet.File[0].FileType != "WAVE" {
+				t.Fatalf("expected a single mix.flac WAVE file, got: %+v", cuesheet.File)
+			}
+			tracks := cuesheet.File[0].Tracks
+			if len(tracks) != len(tt.expected) {
+				t.Fatalf("expected %d tracks, got: %d", len(tt.expected), len(tracks))
+			}
+			for i, w := range tt.expected {
+				track := tracks[i]
+				if track.TrackNumber != w.number || track.Title != w.title || FormatFrame(track.Index[0].Frame) != w.start {
+					t.Errorf("track %d: got (%d, %q, %s), expected (%d, %q, %s)", i, track.TrackNumber,
+						track.Title, FormatFrame(track.Index[0].Frame), w.number, w.title, w.start)
+				}
+			}
+		})
+	}
+
+	for name, input := range map[string]string{
+		"MissingTime":  "01. First - 0:00\n02. Second\n",
+		"OutOfOrder":   "01. First - 3:00\n02. Second - 1:00\n",
+		"InvalidField": "01. First - 0:00\n02. Second - 3:75\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseTracklist(strings.NewReader(input), "mix.flac", "WAVE"); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -10,7 +10,7 @@ import (
 
 func main() {
 	fmt.Println("Mojibake Decoder - Fix double-encoded text")
-	fmt.Println("Supports: UTF-8 misread as Windows-1252, Latin-1, or CP1251 (Cyrillic)")
+	fmt.Println("Supports: UTF-8 misread as Windows-1252, Latin-1, CP1251 or KOI8-R (Cyrillic)")
 	fmt.Println()
 
 	if len(os.Args) < 2 {
@@ -23,6 +23,9 @@ func main() {
 		fmt.Println("  UTF-8 misread as CP1251 (Cyrillic):")
 		fmt.Println("    'Р'СЂР°РІРѕ' → 'Браво' (Bravo)")
 		fmt.Println("    'РџСЂР°РІРѕ' → 'Право' (Right/Law)")
+		fmt.Println()
+		fmt.Println("  UTF-8 misread as KOI8-R (Cyrillic):")
+		fmt.Println("    'п▒я─п╟п╡п╬' → 'Браво' (Bravo)")
 		os.Exit(1)
 	}
 
@@ -40,9 +43,27 @@ func main() {
 		fmt.Printf("  Latin-1/Windows-1252: %s\n", decoded)
 	}
 
-	// Try UTF-8 misread as CP1251 (common for Russian text)
-	if decoded := encoding.DecodeMojibakeFromCP1251(mojibake); utf8.ValidString(decoded) && decoded != mojibake {
-		fmt.Printf("  CP1251 (Cyrillic):    %s ✓\n", decoded)
+	// Try UTF-8 misread as CP1251 (common for Russian text) or KOI8-R (older
+	// Russian rips); the decoding with more Cyrillic letters is marked
+	cp1251 := encoding.DecodeMojibakeFromCP1251(mojibake)
+	koi8r := encoding.DecodeMojibakeFromKOI8R(mojibake)
+	cp1251OK := utf8.ValidString(cp1251) && cp1251 != mojibake
+	koi8rOK := utf8.ValidString(koi8r) && koi8r != mojibake
+	preferKOI8R := koi8rOK && (!cp1251OK || encoding.CountCyrillic(koi8r) > encoding.CountCyrillic(cp1251))
+
+	if cp1251OK {
+		fmt.Printf("  CP1251 (Cyrillic):    %s%s\n", cp1251, mark(!preferKOI8R))
+	}
+	if koi8rOK {
+		fmt.Printf("  KOI8-R (Cyrillic):    %s%s\n", koi8r, mark(preferKOI8R))
+	}
+}
+
+// mark returns the check mark shown after the most plausible decoding
+func mark(best bool) string {
+	if best {
+		return " ✓"
 	}
+	return ""
 }
 
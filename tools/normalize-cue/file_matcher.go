@@ -22,6 +22,21 @@ var AudioExtensions = map[string]bool{
 	".aif":  true,
 }
 
+// addAudioExtensions extends AudioExtensions with a comma-separated list
+// such as ".dsf,tak, .TTA"; entries are lowercased and given a leading dot
+func addAudioExtensions(list string) {
+	for _, ext := range strings.Split(list, ",") {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		AudioExtensions[ext] = true
+	}
+}
+
 // scanAudioFiles scans a directory for audio files
 func scanAudioFiles(dir string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
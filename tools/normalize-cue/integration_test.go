@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -33,7 +34,7 @@ FILE "test.wav" WAVE
 	}
 
 	// Process the CUE file (will backup and replace)
-	changes := processCueFile(cuePath, "", false, false, false)
+	changes := processCueFile(cuePath, "", false, false, false, false)
 
 	if changes == 0 {
 		t.Error("Expected changes but got 0")
@@ -88,7 +89,7 @@ func TestDryRunMode(t *testing.T) {
 	}
 
 	// Process in dry-run mode
-	changes := processCueFile(cuePath, "", true, false, false)
+	changes := processCueFile(cuePath, "", true, false, false, false)
 
 	if changes == 0 {
 		t.Error("Expected changes detection in dry-run mode")
@@ -182,7 +183,7 @@ func TestValidateCueFile(t *testing.T) {
 				defer os.Remove(audioPath)
 			}
 
-			issues := validateCueFile(cuePath)
+			issues := validateCueFile(cuePath, false)
 
 			if tt.expectIssues && len(issues) == 0 {
 				t.Error("Expected issues but got none")
@@ -267,3 +268,147 @@ func TestExtractTrackNumber(t *testing.T) {
 		})
 	}
 }
+
+// TestAddAudioExtensions tests extending the recognized audio extensions
+func TestAddAudioExtensions(t *testing.T) {
+	defer func() {
+		delete(AudioExtensions, ".dsf")
+		delete(AudioExtensions, ".tak")
+	}()
+
+	tmpDir := t.TempDir()
+	for _, f := range []string{"01.dsf", "02.TAK", "03.flac", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, f), []byte("dummy"), 0644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", f, err)
+		}
+	}
+
+	found, err := scanAudioFiles(tmpDir)
+	if err != nil {
+		t.Fatalf("scanAudioFiles failed: %v", err)
+	}
+	if len(found) != 1 {
+		t.Errorf("Expected only the default .flac file before extending, got %v", found)
+	}
+
+	addAudioExtensions(".DSF, tak,")
+
+	found, err = scanAudioFiles(tmpDir)
+	if err != nil {
+		t.Fatalf("scanAudioFiles failed: %v", err)
+	}
+	expected := []string{"01.dsf", "02.TAK", "03.flac"}
+	if strings.Join(found, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, found)
+	}
+}
+
+// TestJSONReport tests the machine-readable dry-run report
+func TestJSONReport(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cueContent := `PERFORMER "РђРіР°С‚Р° РљСЂРёСЃС‚Рё"
+FILE "Album\test.wav" WAVE
+  TRACK 01 AUDIO
+    INDEX 01 00:00:00
+`
+	cuePath := filepath.Join(tmpDir, "test.cue")
+	if err := os.WriteFile(cuePath, []byte(cueContent), 0644); err != nil {
+		t.Fatalf("Failed to create test CUE file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "test.flac"), []byte("dummy"), 0644); err != nil {
+		t.Fatalf("Failed to create test audio file: %v", err)
+	}
+
+	var buf strings.Builder
+	if err := writeJSONReport(&buf, []string{cuePath}, true, false); err != nil {
+		t.Fatalf("writeJSONReport failed: %v", err)
+	}
+
+	var report struct {
+		Files []struct {
+			Path     string `json:"path"`
+			Encoding string `json:"encoding"`
+			Changes  []struct {
+				Kind string `json:"kind"`
+				Line int    `json:"line"`
+				Old  string `json:"old"`
+				New  string `json:"new"`
+			} `json:"changes"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &report); err != nil {
+		t.Fatalf("Invalid JSON report: %v\n%s", err, buf.String())
+	}
+
+	if len(report.Files) != 1 {
+		t.Fatalf("Expected 1 file entry, got %d", len(report.Files))
+	}
+	entry := report.Files[0]
+	if entry.Path != cuePath {
+		t.Errorf("Expected path %q, got %q", cuePath, entry.Path)
+	}
+	if entry.Encoding != "UTF-8" {
+		t.Errorf("Expected UTF-8 encoding, got %q", entry.Encoding)
+	}
+	if len(entry.Changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %+v", entry.Changes)
+	}
+	if c := entry.Changes[0]; c.Kind != "mojibake" || c.Line != 1 || c.Old != "РђРіР°С‚Р° РљСЂРёСЃС‚Рё" || c.New != "Агата Кристи" {
+		t.Errorf("Unexpected mojibake change: %+v", c)
+	}
+	if c := entry.Changes[1]; c.Kind != "file" || c.Line != 2 || c.Old != `Album\test.wav` || c.New != "test.flac" {
+		t.Errorf("Unexpected file change: %+v", c)
+	}
+
+	// The report must not modify anything
+	content, err := os.ReadFile(cuePath)
+	if err != nil {
+		t.Fatalf("Failed to read CUE file: %v", err)
+	}
+	if string(content) != cueContent {
+		t.Error("CUE file should not be modified by the JSON report")
+	}
+}
+
+// TestNormalizeCP1251 tests converting a raw CP1251 CUE file to UTF-8
+func TestNormalizeCP1251(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "cp1251.cue"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	tmpDir := t.TempDir()
+	cuePath := filepath.Join(tmpDir, "album.cue")
+	if err := os.WriteFile(cuePath, fixture, 0644); err != nil {
+		t.Fatalf("Failed to create test CUE file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "01 - Группа крови.flac"), []byte("dummy audio"), 0644); err != nil {
+		t.Fatalf("Failed to create test audio file: %v", err)
+	}
+
+	lines, charset, err := readCueFile(cuePath, true)
+	if err != nil {
+		t.Fatalf("readCueFile failed: %v", err)
+	}
+	if charset != "windows-1251" {
+		t.Errorf("Expected windows-1251, got %q", charset)
+	}
+	if lines[1] != `PERFORMER "Кино"` {
+		t.Errorf("Expected decoded PERFORMER line, got %q", lines[1])
+	}
+
+	outputPath := filepath.Join(tmpDir, "normalized.cue")
+	if changes := processCueFile(cuePath, outputPath, false, false, false, true); changes == 0 {
+		t.Fatal("Expected changes but got 0")
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read normalized file: %v", err)
+	}
+	for _, want := range []string{`TITLE "Закрой за мной дверь"`, `FILE "01 - Группа крови.flac" WAVE`} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected %q in normalized content:\n%s", want, content)
+		}
+	}
+}
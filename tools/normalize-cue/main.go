@@ -11,7 +11,10 @@ var (
 	dryRun      = flag.Bool("d", false, "Dry-run mode: show changes without writing files")
 	verbose     = flag.Bool("v", false, "Verbose output")
 	fixMojibake = flag.Bool("m", false, "Fix mojibake (UTF-8 misread as CP1251) in text fields")
+	cp1251      = flag.Bool("cp1251", false, "Read CUE files as raw CP1251 (Windows-1251) instead of detecting the encoding; cannot be combined with -m")
 	checkMode   = flag.Bool("c", false, "Check mode: validate CUE files and output bash cleanup script for malformed files")
+	jsonReport  = flag.Bool("json", false, "Report proposed changes as JSON without writing files")
+	extraExts   = flag.String("ext", "", "Comma-separated list of additional audio extensions (e.g. .dsf,.tak,.tta)")
 )
 
 func main() {
@@ -32,10 +35,24 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  %s -r /music                    # Recursively process directory\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -r -d /music                 # Recursive dry-run\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -r -c /music > cleanup.sh    # Generate cleanup script for bad files\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -ext .dsf,.tak album.cue     # Also match .dsf and .tak audio files\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -r -json /music > plan.json  # Machine-readable dry-run report\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -cp1251 album.cue            # Convert a Russian CP1251 CUE file to UTF-8\n", os.Args[0])
 	}
 
 	flag.Parse()
 
+	if *extraExts != "" {
+		addAudioExtensions(*extraExts)
+	}
+
+	// Decoding raw CP1251 already yields correct Cyrillic; re-decoding it as
+	// mojibake would garble it
+	if *cp1251 && *fixMojibake {
+		fmt.Fprintf(os.Stderr, "Error: -cp1251 and -m cannot be used together\n")
+		os.Exit(1)
+	}
+
 	if flag.NArg() < 1 {
 		flag.Usage()
 		os.Exit(1)
@@ -51,6 +68,23 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *jsonReport {
+		// JSON report mode never modifies files
+		cueFiles := []string{inputPath}
+		if info.IsDir() {
+			cueFiles, err = findCueFiles(inputPath, *recursive)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading directory: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := writeJSONReport(os.Stdout, cueFiles, *fixMojibake, *cp1251); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if info.IsDir() {
 		// Process directory
 		if outputPath != "" {
@@ -58,9 +92,9 @@ func main() {
 			os.Exit(1)
 		}
 		if *checkMode {
-			checkDirectory(inputPath, *recursive)
+			checkDirectory(inputPath, *recursive, *cp1251)
 		} else {
-			processDirectory(inputPath, *recursive, *dryRun, *verbose, *fixMojibake)
+			processDirectory(inputPath, *recursive, *dryRun, *verbose, *fixMojibake, *cp1251)
 		}
 	} else {
 		// Process single file
@@ -69,7 +103,7 @@ func main() {
 		}
 		if *checkMode {
 			// Check mode for single file
-			if issues := validateCueFile(inputPath); len(issues) > 0 {
+			if issues := validateCueFile(inputPath, *cp1251); len(issues) > 0 {
 				fmt.Fprintf(os.Stderr, "# Validation issues found in: %s\n", inputPath)
 				for _, issue := range issues {
 					fmt.Fprintf(os.Stderr, "#   - %s\n", issue)
@@ -79,7 +113,7 @@ func main() {
 				fmt.Fprintf(os.Stderr, "# File is valid: %s\n", inputPath)
 			}
 		} else {
-			processCueFile(inputPath, outputPath, *dryRun, *verbose, *fixMojibake)
+			processCueFile(inputPath, outputPath, *dryRun, *verbose, *fixMojibake, *cp1251)
 		}
 	}
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,11 +11,43 @@ import (
 
 	"github.com/drgolem/go-cuesheet/cuesheet/encoding"
 	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
 	"golang.org/x/text/transform"
 )
 
 // processDirectory processes all CUE files in a directory
-func processDirectory(dir string, recursive, dryRun, verbose, fixMojibake bool) {
+func processDirectory(dir string, recursive, dryRun, verbose, fixMojibake, forceCP1251 bool) {
+	cueFiles, err := findCueFiles(dir, recursive)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(cueFiles) == 0 {
+		fmt.Printf("No CUE files found in %s\n", dir)
+		return
+	}
+
+	fmt.Printf("Found %d CUE file(s) to process\n\n", len(cueFiles))
+
+	totalProcessed := 0
+	totalChanges := 0
+
+	for i, cueFile := range cueFiles {
+		fmt.Printf("[%d/%d] Processing: %s\n", i+1, len(cueFiles), cueFile)
+		changes := processCueFile(cueFile, "", dryRun, verbose, fixMojibake, forceCP1251)
+		if changes > 0 {
+			totalChanges += changes
+			totalProcessed++
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("Summary: Processed %d file(s) with changes, total %d change(s)\n", totalProcessed, totalChanges)
+}
+
+// findCueFiles lists the CUE files in dir, descending into subdirectories if recursive
+func findCueFiles(dir string, recursive bool) ([]string, error) {
 	var cueFiles []string
 
 	if recursive {
@@ -29,15 +62,13 @@ func processDirectory(dir string, recursive, dryRun, verbose, fixMojibake bool)
 			return nil
 		})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error walking directory: %v\n", err)
-			os.Exit(1)
+			return nil, err
 		}
 	} else {
 		// Only process files in the specified directory (non-recursive)
 		entries, err := os.ReadDir(dir)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading directory: %v\n", err)
-			os.Exit(1)
+			return nil, err
 		}
 		for _, entry := range entries {
 			if !entry.IsDir() && strings.ToLower(filepath.Ext(entry.Name())) == ".cue" {
@@ -46,31 +77,11 @@ func processDirectory(dir string, recursive, dryRun, verbose, fixMojibake bool)
 		}
 	}
 
-	if len(cueFiles) == 0 {
-		fmt.Printf("No CUE files found in %s\n", dir)
-		return
-	}
-
-	fmt.Printf("Found %d CUE file(s) to process\n\n", len(cueFiles))
-
-	totalProcessed := 0
-	totalChanges := 0
-
-	for i, cueFile := range cueFiles {
-		fmt.Printf("[%d/%d] Processing: %s\n", i+1, len(cueFiles), cueFile)
-		changes := processCueFile(cueFile, "", dryRun, verbose, fixMojibake)
-		if changes > 0 {
-			totalChanges += changes
-			totalProcessed++
-		}
-		fmt.Println()
-	}
-
-	fmt.Printf("Summary: Processed %d file(s) with changes, total %d change(s)\n", totalProcessed, totalChanges)
+	return cueFiles, nil
 }
 
 // processCueFile processes a single CUE file
-func processCueFile(cuePath, outputPath string, dryRun, verbose, fixMojibake bool) int {
+func processCueFile(cuePath, outputPath string, dryRun, verbose, fixMojibake, forceCP1251 bool) int {
 	// If no output path specified, we'll backup original and replace it
 	replaceOriginal := (outputPath == "")
 	if outputPath == "" {
@@ -104,7 +115,7 @@ func processCueFile(cuePath, outputPath string, dryRun, verbose, fixMojibake boo
 	}
 
 	// Read and normalize CUE file
-	lines, err := readCueFile(cuePath)
+	lines, _, err := readCueFile(cuePath, forceCP1251)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading CUE file: %v\n", err)
 		if verbose {
@@ -115,7 +126,8 @@ func processCueFile(cuePath, outputPath string, dryRun, verbose, fixMojibake boo
 	}
 
 	// Normalize FILE lines and optionally fix mojibake
-	normalized, changes := normalizeCueLines(lines, audioFiles, verbose, fixMojibake)
+	normalized, lineChanges := normalizeCueLines(lines, audioFiles, verbose, fixMojibake)
+	changes := len(lineChanges)
 
 	if changes == 0 {
 		if verbose {
@@ -169,38 +181,38 @@ func processCueFile(cuePath, outputPath string, dryRun, verbose, fixMojibake boo
 	return changes
 }
 
-// readCueFile reads a CUE file and handles encoding conversion
-func readCueFile(path string) ([]string, error) {
-	file, err := os.Open(path)
+// readCueFile reads a CUE file and converts it to UTF-8
+// The source charset is guessed with encoding.DetectEncoding and returned
+// with the lines; forceCP1251 skips detection and decodes the file as CP1251,
+// for sheets too short for detection to be reliable
+func readCueFile(path string, forceCP1251 bool) ([]string, string, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	defer file.Close()
 
-	// Try reading as UTF-8 first
-	scanner := bufio.NewScanner(file)
-	var lines []string
-	var scanErr error
+	var charset string
+	if forceCP1251 {
+		charset = encoding.Windows1251
+		if data, err = decodeCP1251(data); err != nil {
+			return nil, "", err
+		}
+	} else {
+		charset, _ = encoding.DetectEncoding(data)
+		if decoder := decoderFor(charset); decoder != nil {
+			if data, _, err = transform.Bytes(decoder, data); err != nil {
+				return nil, "", err
+			}
+		}
+	}
 
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	for scanner.Scan() {
 		lines = append(lines, scanner.Text())
 	}
-	scanErr = scanner.Err()
-
-	// If UTF-8 failed, try Windows-1252 (common for DOS-format CUE files)
-	if scanErr != nil || containsInvalidUTF8(lines) {
-		file.Seek(0, 0)
-		decoder := charmap.Windows1252.NewDecoder()
-		reader := transform.NewReader(file, decoder)
-		scanner = bufio.NewScanner(reader)
-		lines = lines[:0] // Clear previous attempt
-
-		for scanner.Scan() {
-			lines = append(lines, scanner.Text())
-		}
-		if err := scanner.Err(); err != nil {
-			return nil, err
-		}
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
 	}
 
 	// Strip UTF-8 BOM from first line if present
@@ -208,23 +220,50 @@ func readCueFile(path string) ([]string, error) {
 		lines[0] = strings.TrimPrefix(lines[0], "\uFEFF")
 	}
 
-	return lines, nil
+	return lines, charset, nil
 }
 
-// containsInvalidUTF8 checks if lines contain invalid UTF-8 sequences
-func containsInvalidUTF8(lines []string) bool {
-	for _, line := range lines {
-		for _, r := range line {
-			if r == '\uFFFD' { // Unicode replacement character
-				return true
-			}
-		}
+// decodeCP1251 converts raw single-byte CP1251 (Windows-1251) text to UTF-8
+// Unlike encoding.DecodeMojibakeFromCP1251 it works on the bytes read from
+// disk, not on text already decoded with the wrong charset
+func decodeCP1251(data []byte) ([]byte, error) {
+	decoded, _, err := transform.Bytes(charmap.Windows1251.NewDecoder(), data)
+	return decoded, err
+}
+
+// decoderFor returns the decoder converting charset to UTF-8, or nil for UTF-8
+func decoderFor(charset string) transform.Transformer {
+	switch charset {
+	case encoding.Windows1251:
+		return charmap.Windows1251.NewDecoder()
+	case encoding.KOI8R:
+		return charmap.KOI8R.NewDecoder()
+	case encoding.ShiftJIS:
+		return japanese.ShiftJIS.NewDecoder()
+	case encoding.Windows1252:
+		return charmap.Windows1252.NewDecoder()
+	default:
+		return nil
 	}
-	return false
+}
+
+// Kinds of line changes made by normalizeCueLines
+const (
+	changeFile     = "file"
+	changeMojibake = "mojibake"
+)
+
+// lineChange records a single modification made while normalizing a CUE file
+type lineChange struct {
+	Kind string `json:"kind"` // changeFile or changeMojibake
+	Line int    `json:"line"` // 1-based line number
+	Old  string `json:"old"`
+	New  string `json:"new"`
 }
 
 // normalizeCueLines normalizes FILE lines and optionally fixes mojibake in CUE content
-func normalizeCueLines(lines []string, audioFiles []string, verbose, fixMojibake bool) ([]string, int) {
+// It returns the normalized lines and the list of changes made
+func normalizeCueLines(lines []string, audioFiles []string, verbose, fixMojibake bool) ([]string, []lineChange) {
 	// Create a map for faster lookups
 	audioMap := make(map[string]string)
 	for _, f := range audioFiles {
@@ -237,11 +276,11 @@ func normalizeCueLines(lines []string, audioFiles []string, verbose, fixMojibake
 	}
 
 	var normalized []string
-	changes := 0
+	var changes []lineChange
 	fileLineRegex := regexp.MustCompile(`^(\s*FILE\s+)"?([^"]+?)"?\s+(WAVE|MP3|AIFF|BINARY|MOTOROLA)?\s*$`)
 	textFieldRegex := regexp.MustCompile(`^(\s*(?:PERFORMER|TITLE|SONGWRITER|COMPOSER|ARRANGER|MESSAGE)\s+)"?([^"]+?)"?\s*$`)
 
-	for _, line := range lines {
+	for i, line := range lines {
 		// Check if this is a text field line that might need mojibake fixing
 		if fixMojibake {
 			textMatches := textFieldRegex.FindStringSubmatch(line)
@@ -256,7 +295,7 @@ func normalizeCueLines(lines []string, audioFiles []string, verbose, fixMojibake
 					}
 					newLine := fmt.Sprintf("%s\"%s\"", prefix, decoded)
 					normalized = append(normalized, newLine)
-					changes++
+					changes = append(changes, lineChange{changeMojibake, i + 1, text, decoded})
 					continue
 				}
 			}
@@ -289,8 +328,8 @@ func normalizeCueLines(lines []string, audioFiles []string, verbose, fixMojibake
 			if verbose {
 				fmt.Printf("  ✓ Fixed: %s -> %s\n", fileName, matchedFile)
 			}
+			changes = append(changes, lineChange{changeFile, i + 1, filePath, matchedFile})
 			fileName = matchedFile
-			changes++
 		} else if matchedFile == "" && len(audioFiles) > 0 {
 			// No match found, but we have audio files
 			if verbose {
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// fileReport describes the changes normalization would make to one CUE file
+type fileReport struct {
+	Path     string       `json:"path"`
+	Encoding string       `json:"encoding,omitempty"`
+	Changes  []lineChange `json:"changes"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// normalizeReport is the JSON document emitted with the -json flag
+type normalizeReport struct {
+	Files []fileReport `json:"files"`
+}
+
+// reportCueFile computes the changes for a CUE file without writing anything
+func reportCueFile(cuePath string, fixMojibake, forceCP1251 bool) fileReport {
+	report := fileReport{Path: cuePath, Changes: []lineChange{}}
+
+	cueDir := filepath.Dir(cuePath)
+	if cueDir == "" || cueDir == "." {
+		var err error
+		cueDir, err = os.Getwd()
+		if err != nil {
+			report.Error = err.Error()
+			return report
+		}
+	}
+
+	audioFiles, err := scanAudioFiles(cueDir)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	lines, encoding, err := readCueFile(cuePath, forceCP1251)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	report.Encoding = encoding
+
+	if _, changes := normalizeCueLines(lines, audioFiles, false, fixMojibake); len(changes) > 0 {
+		report.Changes = changes
+	}
+	return report
+}
+
+// writeJSONReport reports the proposed changes for each CUE file as JSON
+func writeJSONReport(w io.Writer, cueFiles []string, fixMojibake, forceCP1251 bool) error {
+	report := normalizeReport{Files: []fileReport{}}
+	for _, cueFile := range cueFiles {
+		report.Files = append(report.Files, reportCueFile(cueFile, fixMojibake, forceCP1251))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -0,0 +1,10 @@
+REM GENRE Rock
+PERFORMER ""
+TITLE " "
+FILE "01 -  .wav" WAVE
+  TRACK 01 AUDIO
+    TITLE " "
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    TITLE "   "
+    INDEX 01 04:45:00
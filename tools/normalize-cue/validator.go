@@ -9,36 +9,11 @@ import (
 )
 
 // checkDirectory validates all CUE files in a directory and outputs cleanup script
-func checkDirectory(dir string, recursive bool) {
-	var cueFiles []string
-
-	if recursive {
-		// Walk directory recursively
-		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() && strings.ToLower(filepath.Ext(path)) == ".cue" {
-				cueFiles = append(cueFiles, path)
-			}
-			return nil
-		})
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "# Error walking directory: %v\n", err)
-			os.Exit(1)
-		}
-	} else {
-		// Only process files in the specified directory (non-recursive)
-		entries, err := os.ReadDir(dir)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "# Error reading directory: %v\n", err)
-			os.Exit(1)
-		}
-		for _, entry := range entries {
-			if !entry.IsDir() && strings.ToLower(filepath.Ext(entry.Name())) == ".cue" {
-				cueFiles = append(cueFiles, filepath.Join(dir, entry.Name()))
-			}
-		}
+func checkDirectory(dir string, recursive, forceCP1251 bool) {
+	cueFiles, err := findCueFiles(dir, recursive)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "# Error reading directory: %v\n", err)
+		os.Exit(1)
 	}
 
 	if len(cueFiles) == 0 {
@@ -53,7 +28,7 @@ func checkDirectory(dir string, recursive bool) {
 
 	badFiles := 0
 	for _, cueFile := range cueFiles {
-		issues := validateCueFile(cueFile)
+		issues := validateCueFile(cueFile, forceCP1251)
 		if len(issues) > 0 {
 			badFiles++
 			fmt.Printf("# [MALFORMED] %s\n", cueFile)
@@ -72,7 +47,7 @@ func checkDirectory(dir string, recursive bool) {
 }
 
 // validateCueFile validates a CUE file and returns a list of issues
-func validateCueFile(cuePath string) []string {
+func validateCueFile(cuePath string, forceCP1251 bool) []string {
 	var issues []string
 
 	// Check if file exists and get size
@@ -89,7 +64,7 @@ func validateCueFile(cuePath string) []string {
 	}
 
 	// Try to read the file
-	lines, err := readCueFile(cuePath)
+	lines, _, err := readCueFile(cuePath, forceCP1251)
 	if err != nil {
 		issues = append(issues, fmt.Sprintf("Cannot parse file: %v", err))
 		return issues